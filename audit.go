@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/pterm/pterm"
+)
+
+// Decision records how a confirmation was resolved.
+type Decision string
+
+const (
+	DecisionYes           = Decision("yes")
+	DecisionNo            = Decision("no")
+	DecisionAlways        = Decision("always")
+	DecisionAlwaysServer  = Decision("always-server")
+	DecisionTrustedRepo   = Decision("trusted-repo")
+	DecisionTrustedPrefix = Decision("trusted-prefix")
+	DecisionAlwaysRule    = Decision("always-rule")
+	DecisionPolicyAllow   = Decision("policy-allow")
+	DecisionPolicyDeny    = Decision("policy-deny")
+	DecisionNotRequired   = Decision("not-required")
+)
+
+func answerDecision(answer Answer) Decision {
+	switch answer {
+	case Yes:
+		return DecisionYes
+	case Always:
+		return DecisionAlways
+	case AlwaysServer:
+		return DecisionAlwaysServer
+	}
+	return DecisionNo
+}
+
+// AuditRecord is a single line of the confirmation audit log.
+type AuditRecord struct {
+	Time     time.Time      `json:"time"`
+	CallID   string         `json:"callID"`
+	ToolName string         `json:"toolName"`
+	SysTool  string         `json:"sysTool,omitempty"`
+	Repo     string         `json:"repo,omitempty"`
+	Message  string         `json:"message,omitempty"`
+	Input    map[string]any `json:"input,omitempty"`
+	Decision Decision       `json:"decision"`
+	Accepted bool           `json:"accepted"`
+	Reason   string         `json:"reason,omitempty"`
+}
+
+func (c *Confirm) audit(event gptscript.Frame, prompt ConfirmPrompt, decision Decision, reason string) error {
+	if c.auditLog == "" {
+		return nil
+	}
+
+	sysToolName, _ := isSysTool(event, "")
+	record := AuditRecord{
+		Time:     time.Now(),
+		CallID:   event.Call.ID,
+		ToolName: first(event.Call.ToolName, event.Call.Tool.Name),
+		SysTool:  sysToolName,
+		Repo:     c.getRepo(event),
+		Message:  pterm.RemoveColorFromString(prompt.Message),
+		Input:    inputArgs(event),
+		Decision: decision,
+		Accepted: decision != DecisionNo && decision != DecisionPolicyDeny,
+		Reason:   reason,
+	}
+
+	f, err := os.OpenFile(c.auditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(record)
+}
@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+func TestAudit(t *testing.T) {
+	auditLog := filepath.Join(t.TempDir(), "audit.jsonl")
+	c := &Confirm{
+		auditLog:   auditLog,
+		alwaysFile: filepath.Join(t.TempDir(), "always.json"),
+		trustedMap: map[string]struct{}{"github.com/acme/tools": {}},
+		always: []Trusted{
+			{ToolName: "exec", ArgPrefix: map[string]string{"command": "ls"}},
+		},
+		policy: &Policy{
+			Rules: []PolicyRule{
+				{Action: PolicyDeny, Command: "curl", Reason: "no network"},
+			},
+		},
+	}
+
+	withID := func(id string, event gptscript.Frame) gptscript.Frame {
+		event.Call.ID = id
+		return event
+	}
+	repoEvent := withID("repo", sysEvent("", "{}"))
+	repoEvent.Call.Tool.Source.Repo = &gptscript.Repo{Root: "https://github.com/acme/tools.git"}
+
+	events := []gptscript.Frame{
+		withID("manual", sysEvent("#!sys.exec", `{"command": "git status"}`)),
+		withID("rule", sysEvent("#!sys.exec", `{"command": "ls -la"}`)),
+		withID("policy", sysEvent("#!sys.exec", `{"command": "curl https://example.com"}`)),
+		repoEvent,
+	}
+
+	prompted := 0
+	prompter := func(string) (Answer, bool, error) {
+		prompted++
+		return Yes, true, nil
+	}
+	for _, event := range events {
+		if _, _, err := c.ResolveConfirm(context.Background(), event, prompter); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if prompted != 1 {
+		t.Fatalf("expected one manual decision, prompted %d times", prompted)
+	}
+
+	f, err := os.Open(auditLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+
+	want := []struct {
+		callID   string
+		decision Decision
+		accepted bool
+	}{
+		{callID: "manual", decision: DecisionYes, accepted: true},
+		{callID: "rule", decision: DecisionAlwaysRule, accepted: true},
+		{callID: "policy", decision: DecisionPolicyDeny, accepted: false},
+		{callID: "repo", decision: DecisionTrustedRepo, accepted: true},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("expected %d records, got %+v", len(want), records)
+	}
+	for i, w := range want {
+		r := records[i]
+		if r.CallID != w.callID || r.Decision != w.decision || r.Accepted != w.accepted {
+			t.Errorf("record %d = %s %s %v, want %s %s %v", i, r.CallID, r.Decision, r.Accepted, w.callID, w.decision, w.accepted)
+		}
+	}
+	if records[0].SysTool != "exec" || records[0].Input["command"] != "git status" || records[0].Message == "" {
+		t.Errorf("expected the manual record to have the call and the prompt, got %+v", records[0])
+	}
+	if records[2].Reason == "" {
+		t.Errorf("expected the policy reason, got %+v", records[2])
+	}
+}
@@ -8,11 +8,15 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/adrg/xdg"
+	"github.com/fatih/color"
 	"github.com/gptscript-ai/go-gptscript"
 	godiffpatch "github.com/sourcegraph/go-diff-patch"
+	"golang.org/x/exp/maps"
 )
 
 type Confirm struct {
@@ -20,20 +24,68 @@ type Confirm struct {
 	always          []Trusted
 	client          *gptscript.GPTScript
 	authFile        string
+	alwaysFile      string
 	trustedPrefixes []string
+	workspace       string
+	scopeWorkspace  bool
+	policy          *Policy
+	auditLog        string
+	openAPI         OpenAPIConfirm
+}
+
+type ConfirmOptions struct {
+	TrustedRepoPrefixes []string
+	// Workspace is the workspace of the current run, used to scope "always" rules.
+	Workspace string
+	// WorkspaceScopedTrust records new "always" rules against Workspace only instead of
+	// every run of the app.
+	WorkspaceScopedTrust bool
+	// PolicyFile is a JSON policy that decides confirmations before the user is asked.
+	PolicyFile string
+	// AuditLog is a JSONL file every confirmation decision is appended to.
+	AuditLog string
+	// OpenAPI configures the confirmation of OpenAPI operations.
+	OpenAPI *OpenAPIConfirm
 }
 
 func NewConfirm(appName string, client *gptscript.GPTScript, trustedRepoPrefixes ...string) (*Confirm, error) {
+	return NewConfirmWithOptions(appName, client, ConfirmOptions{
+		TrustedRepoPrefixes: trustedRepoPrefixes,
+	})
+}
+
+func NewConfirmWithOptions(appName string, client *gptscript.GPTScript, opts ...ConfirmOptions) (*Confirm, error) {
 	authFile, err := xdg.CacheFile(fmt.Sprintf("%s/authorized.json", appName))
 	if err != nil {
 		return nil, err
 	}
 
+	alwaysFile, err := xdg.CacheFile(fmt.Sprintf("%s/always.json", appName))
+	if err != nil {
+		return nil, err
+	}
+
 	c := &Confirm{
-		trustedMap:      map[string]struct{}{},
-		trustedPrefixes: trustedRepoPrefixes,
-		client:          client,
-		authFile:        authFile,
+		trustedMap: map[string]struct{}{},
+		client:     client,
+		authFile:   authFile,
+		alwaysFile: alwaysFile,
+	}
+
+	for _, opt := range opts {
+		c.trustedPrefixes = append(c.trustedPrefixes, opt.TrustedRepoPrefixes...)
+		c.workspace = first(opt.Workspace, c.workspace)
+		c.scopeWorkspace = first(opt.WorkspaceScopedTrust, c.scopeWorkspace)
+		c.auditLog = first(opt.AuditLog, c.auditLog)
+		if opt.OpenAPI != nil {
+			c.openAPI = *opt.OpenAPI
+		}
+		if opt.PolicyFile != "" {
+			c.policy, err = LoadPolicy(opt.PolicyFile)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	data, err := os.ReadFile(authFile)
@@ -44,22 +96,36 @@ func NewConfirm(appName string, client *gptscript.GPTScript, trustedRepoPrefixes
 	// Don't care if it fails
 	_ = json.Unmarshal(data, &c.trustedMap)
 
+	data, err = os.ReadFile(alwaysFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	// Don't care if it fails
+	_ = json.Unmarshal(data, &c.always)
+
 	return c, nil
 }
 
-func (c *Confirm) HandlePrompt(ctx context.Context, event gptscript.Frame, prompter func(string, bool, bool) (string, bool)) (bool, error) {
+func (c *Confirm) HandlePrompt(ctx context.Context, event gptscript.Frame, prompter PromptFunc) (bool, error) {
 	if !c.IsPromptEvent(event) {
 		return true, nil
 	}
 
+	resp, ok, err := c.ResolvePrompt(ctx, event, prompter)
+	if !ok || err != nil {
+		return ok, err
+	}
+
+	return true, c.client.PromptResponse(ctx, resp)
+}
+
+func (c *Confirm) ResolvePrompt(_ context.Context, event gptscript.Frame, prompter PromptFunc) (gptscript.PromptResponse, bool, error) {
 	if len(event.Prompt.Fields) == 0 {
 		_, ok := prompter(fmt.Sprintf("%s\nHit ENTER to continue...", event.Prompt.Message), event.Prompt.Sensitive, true)
-		if !ok {
-			return ok, nil
-		}
-		return true, c.client.PromptResponse(ctx, gptscript.PromptResponse{
+		return gptscript.PromptResponse{
 			ID: event.Prompt.ID,
-		})
+		}, ok, nil
 	}
 
 	values := map[string]string{}
@@ -75,79 +141,202 @@ func (c *Confirm) HandlePrompt(ctx context.Context, event gptscript.Frame, promp
 
 		v, ok := prompter(msg, event.Prompt.Sensitive, false)
 		if !ok {
-			return ok, nil
+			return gptscript.PromptResponse{}, ok, nil
 		}
 		values[field.Name] = v
 	}
 
-	return true, c.client.PromptResponse(ctx, gptscript.PromptResponse{
+	return gptscript.PromptResponse{
 		ID:        event.Prompt.ID,
 		Responses: values,
-	})
+	}, true, nil
 }
 
-func (c *Confirm) HandleConfirm(ctx context.Context, event gptscript.Frame, prompter func(string) (Answer, bool, error)) (bool, error) {
+func (c *Confirm) HandleConfirm(ctx context.Context, event gptscript.Frame, prompter ConfirmFunc) (bool, error) {
 	if !c.IsConfirmEvent(event) {
 		return true, nil
 	}
 
+	resp, ok, err := c.ResolveConfirm(ctx, event, prompter)
+	if !ok || err != nil {
+		return ok, err
+	}
+
+	return true, c.client.Confirm(ctx, resp)
+}
+
+func (c *Confirm) ResolveConfirm(_ context.Context, event gptscript.Frame, prompter ConfirmFunc) (gptscript.AuthResponse, bool, error) {
 	prompt, trusted, err := c.IsTrusted(event)
 	if err != nil {
-		return true, err
+		return gptscript.AuthResponse{}, true, err
 	}
 
 	var (
-		reason string
-		answer Answer
-		ok     bool
+		reason      string
+		answer      Answer
+		ok          bool
+		decision    = prompt.Decision
+		auditReason = prompt.Reason
 	)
 
-	if !trusted {
+	if prompt.Denied {
+		reason = prompt.Reason
+	} else if !trusted {
 		answer, ok, err = prompter(prompt.Message)
 		if !ok || err != nil {
-			return ok, err
+			return gptscript.AuthResponse{}, ok, err
 		}
+		decision = answerDecision(answer)
 		if answer == No {
 			reason = "User rejected action, abort the current operation and ask the user how to proceed"
+			auditReason = reason
 		} else {
 			trusted = true
-			c.SetTrusted(prompt, answer)
+			if err := c.SetTrusted(prompt, answer); err != nil {
+				return gptscript.AuthResponse{}, true, err
+			}
 		}
 	}
 
-	return true, c.client.Confirm(ctx, gptscript.AuthResponse{
+	if err := c.audit(event, prompt, decision, auditReason); err != nil {
+		return gptscript.AuthResponse{}, true, err
+	}
+
+	return gptscript.AuthResponse{
 		ID:      event.Call.ID,
 		Accept:  trusted,
 		Message: reason,
-	})
+	}, true, nil
 }
 
-func (c *Confirm) SetTrusted(prompt ConfirmPrompt, answer Answer) {
+// SetTrusted records the repository and "always" rule of a prompt the user allowed.
+func (c *Confirm) SetTrusted(prompt ConfirmPrompt, answer Answer) error {
 	if answer == No {
-		return
+		return nil
 	}
 
 	repo := prompt.Repo
 	if _, ok := c.trustedMap[repo]; repo != "" && !ok {
 		c.trustedMap[repo] = struct{}{}
-		data, err := json.Marshal(c.trustedMap)
+		_ = c.saveTrustedMap()
+	}
+
+	trusted := prompt.AlwaysTrust
+	if answer == AlwaysServer {
+		trusted = prompt.ServerTrust
+	}
+	if (answer == Always || answer == AlwaysServer) && trusted.ToolName != "" {
+		if c.scopeWorkspace {
+			trusted.Workspace = c.workspace
+		}
+		err := c.updateAlways(func(rules []Trusted) []Trusted {
+			if slices.ContainsFunc(rules, func(rule Trusted) bool { return rule.String() == trusted.String() }) {
+				return rules
+			}
+			return append(rules, trusted)
+		})
 		if err != nil {
-			return
+			return fmt.Errorf("failed to save rule %s: %w", trusted, err)
 		}
-		_ = os.WriteFile(c.authFile, data, 0600)
 	}
+	return nil
+}
 
-	if answer == Always && prompt.AlwaysTrust.ToolName != "" {
-		c.always = append(c.always, prompt.AlwaysTrust)
+// updateAlways applies update to the saved "always" rules and saves the result. The rules are read again first
+// so sessions of the same app running at the same time don't overwrite each other's rules.
+func (c *Confirm) updateAlways(update func([]Trusted) []Trusted) error {
+	var rules []Trusted
+	data, err := os.ReadFile(c.alwaysFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return fmt.Errorf("failed to read %s: %w", c.alwaysFile, err)
+		}
 	}
+
+	rules = update(rules)
+	data, err = json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.alwaysFile, data, 0600); err != nil {
+		return err
+	}
+	c.always = rules
+	return nil
+}
+
+func (c *Confirm) saveTrustedMap() error {
+	data, err := json.Marshal(c.trustedMap)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.authFile, data, 0600)
+}
+
+// ListTrusted returns the trusted git repositories and "always" rules that have been recorded, sorted.
+func (c *Confirm) ListTrusted() (repos []string, rules []Trusted) {
+	repos = maps.Keys(c.trustedMap)
+	sort.Strings(repos)
+	rules = slices.Clone(c.always)
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].String() < rules[j].String()
+	})
+	return repos, rules
+}
+
+// Revoke removes the trusted repositories and "always" rules matching target. Target can be a repository,
+// a repository prefix, a tool name, an MCP server or the string form of a rule. The number of removed entries is returned.
+func (c *Confirm) Revoke(target string) (int, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return 0, fmt.Errorf("nothing to revoke")
+	}
+
+	var removed int
+	for repo := range c.trustedMap {
+		if repo == target || strings.HasPrefix(repo, target+"/") {
+			delete(c.trustedMap, repo)
+			removed++
+		}
+	}
+	if removed > 0 {
+		if err := c.saveTrustedMap(); err != nil {
+			return removed, err
+		}
+	}
+
+	err := c.updateAlways(func(rules []Trusted) []Trusted {
+		count := len(rules)
+		rules = slices.DeleteFunc(rules, func(trusted Trusted) bool {
+			return trusted.ToolName == target || trusted.MCPServer == target || trusted.String() == target
+		})
+		removed += count - len(rules)
+		return rules
+	})
+	return removed, err
+}
+
+// ResetAll forgets every trusted repository and "always" rule.
+func (c *Confirm) ResetAll() error {
+	c.trustedMap = map[string]struct{}{}
+	c.always = nil
+	for _, file := range []string{c.authFile, c.alwaysFile} {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
 }
 
 func (c *Confirm) IsConfirmEvent(event gptscript.Frame) bool {
-	return event.Call != nil && event.Call.Type == gptscript.EventTypeCallConfirm
+	return isConfirmEvent(event)
 }
 
 func (c *Confirm) IsPromptEvent(event gptscript.Frame) bool {
-	return event.Prompt != nil && event.Prompt.Type == gptscript.EventTypePrompt
+	return isPromptEvent(event)
 }
 
 func (c *Confirm) getRepo(event gptscript.Frame) string {
@@ -161,40 +350,146 @@ func (c *Confirm) getRepo(event gptscript.Frame) string {
 	return ""
 }
 
-func (c *Confirm) isAlways(event gptscript.Frame) bool {
+func (c *Confirm) isAlways(event gptscript.Frame) (Trusted, bool) {
 	sysToolName, isSysTool := isSysTool(event, "")
 	if !isSysTool {
-		return false
+		return Trusted{}, false
 	}
 
-	for _, trusted := range c.always {
-		if trusted.ToolName == sysToolName {
-			if len(c.trustedPrefixes) == 0 {
-				return true
+	args := inputArgs(event)
+	if server, tool, ok := mcpInvocation(sysToolName); ok {
+		for _, trusted := range c.rules("mcp") {
+			if trusted.MCPServer == server && (trusted.MCPTool == "" || trusted.MCPTool == tool) {
+				return trusted, true
+			}
+		}
+		return Trusted{}, false
+	} else if sysToolName == "exec" {
+		return c.isAlwaysExec(args)
+	} else if strings.HasPrefix(sysToolName, "openapi ") {
+		args = openAPIArgs(args)
+	}
+
+	for _, trusted := range c.rules(sysToolName) {
+		if slices.Contains([]string{"write", "append", "download"}, sysToolName) && !c.inWorkspace(args) {
+			// Writes outside the workspace are always confirmed so the warning is shown
+			continue
+		}
+		if trusted.matches(args) {
+			return trusted, true
+		}
+	}
+
+	return Trusted{}, false
+}
+
+// isAlwaysExec returns true only if every command of a compound command line is covered by an "always"
+// rule. Commands that redirect output to files are never covered, the commands of scripts run by a shell or
+// eval have to be covered themselves.
+func (c *Confirm) isAlwaysExec(args map[string]any) (Trusted, bool) {
+	command, _ := args["command"].(string)
+	segments := parseShell(command)
+	if len(segments) == 0 {
+		return Trusted{}, false
+	}
+
+	var (
+		rules   = c.rules("exec")
+		matched Trusted
+	)
+	for _, segment := range segments {
+		if segment.Risk() == RiskDestructive || len(segment.redirects) > 0 {
+			return Trusted{}, false
+		}
+
+		segmentArgs := maps.Clone(args)
+		if script, ok := shellScript(segment.args); ok {
+			// A script file is never covered, the commands of an inline script are checked one by one
+			if script == "" {
+				return Trusted{}, false
 			}
-			args := inputArgs(event)
-			for name, prefix := range trusted.ArgPrefix {
-				val, _ := args[name].(string)
-				if !strings.HasPrefix(val, prefix) {
-					return false
-				}
+			segmentArgs["command"] = script
+			trusted, ok := c.isAlwaysExec(segmentArgs)
+			if !ok {
+				return Trusted{}, false
 			}
-			return true
+			if matched.ToolName == "" {
+				matched = trusted
+			}
+			continue
 		}
+		segmentArgs["command"] = segment.String()
+
+		i := slices.IndexFunc(rules, func(trusted Trusted) bool {
+			return trusted.matches(segmentArgs)
+		})
+		if i < 0 {
+			return Trusted{}, false
+		}
+		if matched.ToolName == "" {
+			matched = rules[i]
+		}
+	}
+
+	return matched, true
+}
+
+// rules returns the "always" rules for the sys tool that apply to the current workspace.
+func (c *Confirm) rules(sysToolName string) (result []Trusted) {
+	for _, trusted := range c.always {
+		if trusted.ToolName != sysToolName {
+			continue
+		}
+		if trusted.Workspace != "" && trusted.Workspace != c.workspace {
+			continue
+		}
+		result = append(result, trusted)
 	}
+	return
+}
 
-	return false
+func (c *Confirm) inWorkspace(args map[string]any) bool {
+	filename := pathArg(args)
+	return c.workspace == "" || withinDir(resolvePath(filename), resolvePath(c.workspace))
 }
 
 func (c *Confirm) IsTrusted(event gptscript.Frame) (ConfirmPrompt, bool, error) {
+	action, reason := c.policy.Evaluate(event)
+	switch action {
+	case PolicyAllow:
+		return ConfirmPrompt{
+			Decision: DecisionPolicyAllow,
+			Reason:   reason,
+		}, true, nil
+	case PolicyDeny:
+		if reason == "" {
+			reason = "Action denied by policy"
+		}
+		return ConfirmPrompt{
+			Denied:   true,
+			Decision: DecisionPolicyDeny,
+			Reason:   reason + ", abort the current operation and ask the user how to proceed",
+		}, false, nil
+	case PolicyAsk:
+		sysToolName, _ := isSysTool(event, "")
+		// The policy is evaluated before the rules on every call, so an always rule would never be used
+		return c.toSysConfirmMessage(sysToolName, event, false), false, nil
+	}
+
 	repo := c.getRepo(event)
 	if _, ok := c.trustedMap[repo]; repo != "" && ok {
-		return ConfirmPrompt{}, true, nil
+		return ConfirmPrompt{
+			Decision: DecisionTrustedRepo,
+			Reason:   "previously trusted repository " + repo,
+		}, true, nil
 	}
 
 	for _, prefix := range c.trustedPrefixes {
 		if repo == prefix || strings.HasPrefix(repo, prefix+"/") {
-			return ConfirmPrompt{}, true, nil
+			return ConfirmPrompt{
+				Decision: DecisionTrustedPrefix,
+				Reason:   "repository matches trusted prefix " + prefix,
+			}, true, nil
 		}
 	}
 
@@ -205,20 +500,27 @@ func (c *Confirm) IsTrusted(event gptscript.Frame) (ConfirmPrompt, bool, error)
 		}, false, nil
 	}
 
-	if c.isAlways(event) {
-		return ConfirmPrompt{}, true, nil
+	if trusted, ok := c.isAlways(event); ok {
+		return ConfirmPrompt{
+			Decision: DecisionAlwaysRule,
+			Reason:   "matches always rule " + trusted.String(),
+		}, true, nil
 	}
 
 	if sysToolName, isSysTool := isSysTool(event, ""); isSysTool {
-		return toSysConfirmMessage(sysToolName, event), false, nil
+		return c.toSysConfirmMessage(sysToolName, event, true), false, nil
 	}
 
-	return ConfirmPrompt{}, true, nil
+	return ConfirmPrompt{
+		Decision: DecisionNotRequired,
+	}, true, nil
 }
 
 func isSysTool(event gptscript.Frame, sysName string) (string, bool) {
-	return strings.TrimPrefix(event.Call.Tool.Instructions[2:], "sys."),
-		strings.HasPrefix(event.Call.Tool.Instructions, "#!sys."+sysName)
+	if !strings.HasPrefix(event.Call.Tool.Instructions, "#!sys."+sysName) {
+		return "", false
+	}
+	return strings.TrimPrefix(event.Call.Tool.Instructions, "#!sys."), true
 }
 
 func inputArgs(event gptscript.Frame) map[string]any {
@@ -231,28 +533,115 @@ type ConfirmPrompt struct {
 	Repo        string
 	Message     string
 	AlwaysTrust Trusted
+	// ServerTrust is recorded when the user allows every tool of an MCP server
+	ServerTrust Trusted
+	// Denied is set when the call is rejected without asking, Reason is sent back to the model
+	Denied bool
+	Reason string
+	// Decision is set when the call was resolved without asking the user
+	Decision Decision
 }
 
 type Trusted struct {
-	ToolName  string
-	ArgPrefix map[string]string
+	ToolName  string            `json:"toolName"`
+	ArgPrefix map[string]string `json:"argPrefix,omitempty"`
+	// ArgEquals requires arguments to have exactly these values
+	ArgEquals map[string]string `json:"argEquals,omitempty"`
+	// MCPServer and MCPTool limit an "mcp" rule to a server, and optionally one of its tools
+	MCPServer string `json:"mcpServer,omitempty"`
+	MCPTool   string `json:"mcpTool,omitempty"`
+	// PathPrefix limits the rule to calls whose resolved path argument is under this directory
+	PathPrefix string `json:"pathPrefix,omitempty"`
+	// Workspace limits the rule to runs in this workspace, empty means all workspaces.
+	Workspace string `json:"workspace,omitempty"`
 }
 
-func toSysConfirmMessage(toolName string, event gptscript.Frame) (prompt ConfirmPrompt) {
+func (t Trusted) String() string {
+	buf := &strings.Builder{}
+	buf.WriteString(t.ToolName)
+
+	keys := maps.Keys(t.ArgPrefix)
+	sort.Strings(keys)
+	for _, key := range keys {
+		buf.WriteString(fmt.Sprintf(" %s=%q", key, t.ArgPrefix[key]))
+	}
+	keys = maps.Keys(t.ArgEquals)
+	sort.Strings(keys)
+	for _, key := range keys {
+		buf.WriteString(fmt.Sprintf(" %s==%q", key, t.ArgEquals[key]))
+	}
+	if t.MCPServer != "" {
+		buf.WriteString(fmt.Sprintf(" server=%q", t.MCPServer))
+	}
+	if t.MCPTool != "" {
+		buf.WriteString(fmt.Sprintf(" tool=%q", t.MCPTool))
+	}
+	if t.PathPrefix != "" {
+		buf.WriteString(fmt.Sprintf(" path=%q", t.PathPrefix))
+	}
+
+	return buf.String()
+}
+
+func (t Trusted) matches(args map[string]any) bool {
+	if t.PathPrefix != "" {
+		filename := pathArg(args)
+		if filename == "" || !withinDir(resolvePath(filename), t.PathPrefix) {
+			return false
+		}
+	}
+	for name, prefix := range t.ArgPrefix {
+		val, _ := args[name].(string)
+		if name == "command" {
+			// Match whole words so "git status" does not allow "git statusx"
+			if val != prefix && !strings.HasPrefix(val, prefix+" ") {
+				return false
+			}
+		} else if !strings.HasPrefix(val, prefix) {
+			return false
+		}
+	}
+	for name, expected := range t.ArgEquals {
+		if val, ok := args[name]; !ok || argString(val) != expected {
+			return false
+		}
+	}
+	return true
+}
+
+// toSysConfirmMessage builds the prompt for a call. The user is only offered to always allow calls like it if
+// offerAlways is set.
+func (c *Confirm) toSysConfirmMessage(toolName string, event gptscript.Frame, offerAlways bool) (prompt ConfirmPrompt) {
 	var ok bool
 
 	switch toolName {
 	case "write":
-		prompt, ok = toWritePrompt(event)
+		prompt, ok = toWritePrompt(event, c.workspace, offerAlways)
+	case "append":
+		prompt, ok = toAppendPrompt(event, c.workspace, offerAlways)
 	case "exec":
-		prompt, ok = toExecPrompt(event)
+		prompt, ok = toExecPrompt(event, offerAlways)
+	case "read":
+		prompt, ok = toReadPrompt(event, offerAlways)
+	case "ls":
+		prompt, ok = toListPrompt(event, offerAlways)
+	case "remove":
+		prompt, ok = toRemovePrompt(event, c.workspace)
+	case "download":
+		prompt, ok = toDownloadPrompt(event, c.workspace, offerAlways)
+	case "http.get", "http.html2text", "http.post":
+		prompt, ok = toHTTPPrompt(toolName, event, offerAlways)
+	default:
+		if server, tool, isMCP := mcpInvocation(toolName); isMCP {
+			prompt, ok = toMCPPrompt(server, tool, event, offerAlways)
+		}
 	}
 	if ok {
 		return
 	}
 
-	if strings.HasPrefix(toolName, "openapi ") && os.Getenv("GPTSCRIPT_OPENAPI_REVAMP") == "true" {
-		prompt, ok = toOpenAPIPrompt(toolName, event)
+	if strings.HasPrefix(toolName, "openapi ") && !c.openAPI.Disable {
+		prompt, ok = c.openAPI.toPrompt(toolName, event, offerAlways)
 		if ok {
 			return
 		}
@@ -263,92 +652,36 @@ func toSysConfirmMessage(toolName string, event gptscript.Frame) (prompt Confirm
 		text = strings.ToLower(event.Call.DisplayText[:1]) + event.Call.DisplayText[1:]
 	}
 
-	fields := strings.Fields(toolName)
-	if suffix, ok := strings.CutPrefix(strings.Fields(toolName)[0], "mcp.invoke."); ok {
-		toolName = "MCP " + suffix
-	}
-	if text == "sys.mcp.invoke" && len(fields) >= 3 {
-		text = "MCP " + strings.Join(fields[2:], " ")
-		toolName = strings.Join(fields[:2], " ")
-	}
-
-	return ConfirmPrompt{
-		Message: fmt.Sprintf("Proceed with %s (or allow all %s calls)\nConfirm (y/n/a)", text, toolName),
-		AlwaysTrust: Trusted{
-			ToolName: toolName,
-		},
-	}
+	return confirmPrompt("Proceed with "+text, fmt.Sprintf(" (or allow all %s calls)", toolName), Trusted{
+		ToolName: toolName,
+	}, offerAlways)
 }
 
-func toOpenAPIPrompt(toolName string, event gptscript.Frame) (ConfirmPrompt, bool) {
-	instructions := strings.Fields(event.Call.Tool.Instructions)
-	if len(instructions) != 4 {
-		return ConfirmPrompt{}, false
-	}
-
-	var (
-		command  = instructions[1]
-		oapiFile = instructions[2]
-	)
-
-	data := inputArgs(event)
-
-	switch command {
-	case "list":
-		return ConfirmPrompt{
-			Message: fmt.Sprintf("List operations in OpenAPI file %s\nConfirm (y/n)", oapiFile),
-		}, true
-	case "get-schema":
-		operation, _ := data["operation"].(string)
-		if operation == "" {
-			return ConfirmPrompt{}, false
-		}
-
+// confirmPrompt ends the message of a prompt with the answers the user has. The offer to always allow calls
+// like this one with rule is only added if offerAlways is set.
+func confirmPrompt(msg, offer string, rule Trusted, offerAlways bool) ConfirmPrompt {
+	if !offerAlways {
 		return ConfirmPrompt{
-			Message: fmt.Sprintf("Get schema for operation %s in OpenAPI file %s\nConfirm (y/n)", operation, oapiFile),
-		}, true
-	case "run":
-		operation, _ := data["operation"].(string)
-		args, _ := data["args"].(map[string]any)
-		if operation == "" {
-			return ConfirmPrompt{}, false
+			Message: strings.TrimRight(msg, "\n") + "\nConfirm (y/n)",
 		}
-
-		msg := &strings.Builder{}
-		msg.WriteString("Run operation ")
-		msg.WriteString(operation)
-		if len(args) > 0 {
-			msg.WriteString(" with arguments ")
-			msg.WriteString(fmt.Sprintf("%v", args))
-		}
-		msg.WriteString(" in OpenAPI file ")
-		msg.WriteString(oapiFile)
-
-		msg.WriteString(" (or allow all ")
-		msg.WriteString(operation)
-		msg.WriteString(" calls)\nConfirm (y/n/a)")
-
-		return ConfirmPrompt{
-			Message: msg.String(),
-			AlwaysTrust: Trusted{
-				ToolName: toolName,
-				ArgPrefix: map[string]string{
-					"operation": operation,
-				},
-			},
-		}, true
 	}
-
-	return ConfirmPrompt{}, false
+	return ConfirmPrompt{
+		Message:     msg + offer + "\nConfirm (y/n/a)",
+		AlwaysTrust: rule,
+	}
 }
 
-func toExecPrompt(event gptscript.Frame) (ConfirmPrompt, bool) {
+func toExecPrompt(event gptscript.Frame, offerAlways bool) (ConfirmPrompt, bool) {
 	data := inputArgs(event)
 	command, _ := data["command"].(string)
 	directory, _ := data["directory"].(string)
-	if command == "" {
+	segments := parseShell(command)
+	if len(segments) == 0 {
 		return ConfirmPrompt{}, false
 	}
+
+	risk := shellRisk(segments)
+
 	msg := &strings.Builder{}
 	msg.WriteString("Run \"")
 	msg.WriteString(command)
@@ -357,29 +690,70 @@ func toExecPrompt(event gptscript.Frame) (ConfirmPrompt, bool) {
 		msg.WriteString(" in directory ")
 		msg.WriteString(directory)
 	}
+	if len(segments) == 1 {
+		msg.WriteString(" ")
+		msg.WriteString(riskLabel(risk))
+	} else {
+		for _, segment := range segments {
+			msg.WriteString("\n  ")
+			msg.WriteString(riskLabel(segment.Risk()))
+			msg.WriteString(" ")
+			msg.WriteString(segment.String())
+		}
+	}
+
+	if risk == RiskDestructive {
+		// Never offer to always allow destructive or privileged commands
+		return confirmPrompt(msg.String(), "", Trusted{}, false), true
+	}
 
-	parts := strings.Fields(command)
+	// A segment can be only a redirect, the rule is offered for the first command that is run. Rules never
+	// cover a shell or eval itself, only the commands of its script.
+	i := slices.IndexFunc(segments, func(segment shellSegment) bool {
+		return len(segment.args) > 0
+	})
+	if i >= 0 {
+		if _, isShell := shellScript(segments[i].args); isShell {
+			i = -1
+		}
+	}
+	if i < 0 {
+		return confirmPrompt(msg.String(), "", Trusted{}, false), true
+	}
+
+	parts := segments[i].args
 	prefix := parts[0]
 	if len(parts) > 1 && !strings.HasPrefix(parts[1], "-") && !strings.Contains(parts[1], ".") {
 		prefix += " " + parts[1]
 	}
 
-	msg.WriteString(" (or allow all \"")
-	msg.WriteString(prefix)
-	msg.WriteString(" ...\" commands)\nConfirm (y/n/a)")
+	offer := fmt.Sprintf(" (or allow all \"%s ...\" commands)", prefix)
+	if len(segments) > 1 {
+		offer = "\n" + offer[1:]
+	}
 
-	return ConfirmPrompt{
-		Message: msg.String(),
-		AlwaysTrust: Trusted{
-			ToolName: "exec",
-			ArgPrefix: map[string]string{
-				"command": prefix,
-			},
+	return confirmPrompt(msg.String(), offer, Trusted{
+		ToolName: "exec",
+		ArgPrefix: map[string]string{
+			"command": prefix,
 		},
-	}, true
+	}, offerAlways), true
+}
+
+func riskLabel(risk Risk) string {
+	label := "[" + risk.String() + "]"
+	switch risk {
+	case RiskReadOnly:
+		return color.GreenString(label)
+	case RiskMutating:
+		return color.YellowString(label)
+	case RiskNetwork:
+		return color.CyanString(label)
+	}
+	return WarningStyle.Sprint(label)
 }
 
-func toWritePrompt(event gptscript.Frame) (ConfirmPrompt, bool) {
+func toWritePrompt(event gptscript.Frame, workspace string, offerAlways bool) (ConfirmPrompt, bool) {
 	data := inputArgs(event)
 	filename, _ := data["filename"].(string)
 	content, _ := data["content"].(string)
@@ -387,19 +761,37 @@ func toWritePrompt(event gptscript.Frame) (ConfirmPrompt, bool) {
 		return ConfirmPrompt{}, false
 	}
 
+	var (
+		resolved = resolvePath(filename)
+		dir      = filepath.Dir(resolved)
+		msg      = &strings.Builder{}
+	)
+
 	existing, err := os.ReadFile(filename)
 	if errors.Is(err, fs.ErrNotExist) {
-		return ConfirmPrompt{
-			Message: fmt.Sprintf("%s\nWrite to %s \nConfirm (y/n)",
-				markdownBox("", content), filename),
-		}, true
+		msg.WriteString(markdownBox("", content))
+		msg.WriteString("\n")
 	} else if err == nil {
 		patch := godiffpatch.GeneratePatch(filepath.Base(filename), string(existing), content)
-		return ConfirmPrompt{
-			Message: fmt.Sprintf("%s\nUpdate %s\nConfirm (y/n)",
-				markdownBox("diff", patch), filename),
-		}, true
+		msg.WriteString(markdownBox("diff", patch))
+		msg.WriteString("\n")
+	} else {
+		return ConfirmPrompt{}, false
+	}
+
+	warning := workspaceWarning(resolved, workspace)
+	msg.WriteString(warning)
+
+	if err != nil {
+		msg.WriteString("Write to ")
+	} else {
+		msg.WriteString("Update ")
 	}
+	msg.WriteString(filename)
 
-	return ConfirmPrompt{}, false
+	// Rules never cover writes outside the workspace, so there is nothing to always allow
+	return confirmPrompt(msg.String(), " (or allow all writes under "+dir+")", Trusted{
+		ToolName:   "write",
+		PathPrefix: dir,
+	}, offerAlways && warning == ""), true
 }
@@ -0,0 +1,240 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+const callTreeHelp = "<n> expand/collapse, d <n> show/hide input and output, e expand all, c collapse all, q quit"
+
+type callStatus int
+
+const (
+	callRunning callStatus = iota
+	callDone
+	callFailed
+)
+
+func (s callStatus) icon() string {
+	switch s {
+	case callDone:
+		return color.GreenString("✓")
+	case callFailed:
+		return color.RedString("✗")
+	default:
+		return color.YellowString("…")
+	}
+}
+
+// callTree is a collapsible view of the calls of a turn.
+type callTree struct {
+	calls    gptscript.CallFrames
+	children map[string][]string
+	roots    []string
+	// failed is set when the turn ended in an error, calls that never finished failed with it
+	failed   bool
+	denied   map[string]bool
+	expanded map[string]bool
+	details  map[string]bool
+	rows     []string
+	message  string
+}
+
+func newCallTree(turn transcriptTurn) *callTree {
+	t := &callTree{
+		calls:    turn.Calls,
+		children: map[string][]string{},
+		failed:   turn.State == gptscript.Error,
+		denied:   map[string]bool{},
+		expanded: map[string]bool{},
+		details:  map[string]bool{},
+	}
+
+	for id, resp := range turn.Decisions {
+		t.denied[id] = !resp.Accept
+	}
+
+	for id, call := range turn.Calls {
+		if _, ok := turn.Calls[call.ParentID]; ok && call.ParentID != id {
+			t.children[call.ParentID] = append(t.children[call.ParentID], id)
+		} else {
+			t.roots = append(t.roots, id)
+		}
+	}
+
+	t.sort(t.roots)
+	for _, ids := range t.children {
+		t.sort(ids)
+	}
+	for _, id := range t.roots {
+		t.expanded[id] = true
+	}
+	return t
+}
+
+func (t *callTree) sort(ids []string) {
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := t.calls[ids[i]], t.calls[ids[j]]
+		if a.Start.Equal(b.Start) {
+			return ids[i] < ids[j]
+		}
+		return a.Start.Before(b.Start)
+	})
+}
+
+func (t *callTree) status(call gptscript.CallFrame) callStatus {
+	switch {
+	case t.denied[call.ID]:
+		return callFailed
+	case !call.End.IsZero():
+		return callDone
+	case t.failed:
+		return callFailed
+	default:
+		return callRunning
+	}
+}
+
+func callDuration(call gptscript.CallFrame) time.Duration {
+	if call.Start.IsZero() {
+		return 0
+	}
+	end := call.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+	d := max(end.Sub(call.Start), 0)
+	if d < time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(100 * time.Millisecond)
+}
+
+func callToolName(call gptscript.CallFrame) string {
+	if call.ToolName != "" {
+		return call.ToolName
+	}
+	if call.Tool.Name != "" {
+		return call.Tool.Name
+	}
+	return call.ID
+}
+
+func (t *callTree) view() string {
+	t.rows = t.rows[:0]
+	buf := &strings.Builder{}
+	for _, id := range t.roots {
+		t.viewCall(buf, id, 0, map[string]bool{})
+	}
+	if len(t.rows) == 0 {
+		buf.WriteString("No calls\n")
+	}
+	if t.message != "" {
+		buf.WriteString(t.message)
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+func (t *callTree) viewCall(buf *strings.Builder, id string, depth int, seen map[string]bool) {
+	if seen[id] {
+		return
+	}
+	seen[id] = true
+
+	call := t.calls[id]
+	t.rows = append(t.rows, id)
+
+	marker := " "
+	if len(t.children[id]) > 0 {
+		marker = "▸"
+		if t.expanded[id] {
+			marker = "▾"
+		}
+	}
+
+	stats := callDuration(call).String()
+	if call.Usage.TotalTokens > 0 {
+		stats += fmt.Sprintf(", %s tokens", formatCount(call.Usage.TotalTokens))
+	}
+	fmt.Fprintf(buf, "%3d %s%s %s %s  %s\n", len(t.rows), strings.Repeat("  ", depth), marker,
+		t.status(call).icon(), callToolName(call), color.HiBlackString(stats))
+
+	if t.details[id] {
+		indent := strings.Repeat("  ", depth+3)
+		if call.Input != "" {
+			buf.WriteString(indentLines(renderBox("Input:\n\n"+strings.TrimSpace(call.Input)), indent))
+		}
+		if output := callText(call); output != "" {
+			buf.WriteString(indentLines(renderBox("Output:\n\n"+strings.TrimSpace(output)), indent))
+		}
+	}
+
+	if t.expanded[id] {
+		for _, child := range t.children[id] {
+			t.viewCall(buf, child, depth+1, seen)
+		}
+	}
+}
+
+func indentLines(s, indent string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	return indent + strings.Join(lines, "\n"+indent) + "\n"
+}
+
+func (t *callTree) row(arg string) (string, bool) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(t.rows) {
+		t.message = fmt.Sprintf("no call %s", arg)
+		return "", false
+	}
+	return t.rows[n-1], true
+}
+
+// handle applies a command and returns false when the view should close.
+func (t *callTree) handle(cmd string) bool {
+	t.message = ""
+	fields := strings.Fields(cmd)
+	switch {
+	case len(fields) == 0:
+		t.message = callTreeHelp
+	case fields[0] == "q":
+		return false
+	case fields[0] == "e":
+		for id := range t.children {
+			t.expanded[id] = true
+		}
+	case fields[0] == "c":
+		t.expanded = map[string]bool{}
+	case fields[0] == "d" && len(fields) == 2:
+		if id, ok := t.row(fields[1]); ok {
+			t.details[id] = !t.details[id]
+		}
+	case len(fields) == 1 && fields[0][0] >= '0' && fields[0][0] <= '9':
+		if id, ok := t.row(fields[0]); ok {
+			t.expanded[id] = !t.expanded[id]
+		}
+	default:
+		t.message = callTreeHelp
+	}
+	return true
+}
+
+// browse shows the tree until the user quits or the prompt is closed.
+func (t *callTree) browse(out io.Writer, prompt func(string) (string, bool)) {
+	for {
+		fmt.Fprint(out, "\033[H\033[2J")
+		fmt.Fprint(out, t.view())
+		cmd, ok := prompt("calls> ")
+		if !ok || !t.handle(cmd) {
+			return
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/pterm/pterm"
+)
+
+func TestCallTree(t *testing.T) {
+	start := time.Now()
+	call := func(id, parent, name string, offset, duration time.Duration) gptscript.CallFrame {
+		c := gptscript.CallFrame{
+			CallContext: gptscript.CallContext{ID: id, ParentID: parent, ToolName: name},
+			Start:       start.Add(offset),
+			Input:       name + " input",
+			Output:      []gptscript.Output{{Content: name + " output"}},
+		}
+		if duration > 0 {
+			c.End = c.Start.Add(duration)
+		}
+		return c
+	}
+
+	tree := newCallTree(transcriptTurn{
+		Calls: gptscript.CallFrames{
+			"1": call("1", "", "chat", 0, 3*time.Second),
+			"2": call("2", "1", "ls", time.Millisecond, 250*time.Millisecond),
+			"3": call("3", "1", "exec", 2*time.Millisecond, 0),
+			"4": call("4", "2", "nested", 3*time.Millisecond, time.Millisecond),
+		},
+		Decisions: map[string]gptscript.AuthResponse{"3": {Accept: false}},
+	})
+
+	view := pterm.RemoveColorFromString(tree.view())
+	for _, want := range []string{"1 ▾ ✓ chat  3s", "2   ▸ ✓ ls  250ms", "3     ✗ exec"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "nested") {
+		t.Errorf("expected nested call to be collapsed:\n%s", view)
+	}
+
+	tree.handle("2")
+	tree.handle("d 3")
+	view = pterm.RemoveColorFromString(tree.view())
+	for _, want := range []string{"3       ✓ nested", "exec input", "exec output"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in:\n%s", want, view)
+		}
+	}
+
+	tree.handle("c")
+	if rows := strings.Count(pterm.RemoveColorFromString(tree.view()), "\n"); rows != 1 {
+		t.Errorf("expected only the root after collapsing, got %d rows", rows)
+	}
+
+	tree.handle("9")
+	if !strings.Contains(tree.view(), "no call 9") {
+		t.Errorf("expected missing call message")
+	}
+	if tree.handle("q") {
+		t.Error("expected q to close the tree")
+	}
+}
@@ -0,0 +1,288 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"golang.org/x/exp/maps"
+)
+
+// chatCommand is a slash command that can be entered at the chat prompt instead of a message. Commands
+// that resubmit a message implement submit instead of run.
+type chatCommand struct {
+	usage  string
+	run    func(args []string) error
+	submit func(args []string) (string, error)
+}
+
+type chatCommands map[string]chatCommand
+
+// handle runs the command in line. Lines that are not a known command are not handled so they can be
+// sent to the model as is. A non-empty result is a message the command wants to send.
+func (c chatCommands) handle(line string) (string, bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", false, nil
+	}
+
+	name, ok := strings.CutPrefix(fields[0], "/")
+	if !ok {
+		return "", false, nil
+	}
+
+	if name == "help" {
+		fmt.Print(c.help())
+		return "", true, nil
+	}
+
+	cmd, ok := c[name]
+	if !ok {
+		return "", false, nil
+	}
+
+	if cmd.submit != nil {
+		submit, err := cmd.submit(fields[1:])
+		return submit, true, err
+	}
+	return "", true, cmd.run(fields[1:])
+}
+
+func (c chatCommands) help() string {
+	buf := &strings.Builder{}
+	names := maps.Keys(c)
+	sort.Strings(names)
+	for _, name := range names {
+		buf.WriteString(fmt.Sprintf("  /%s\n", c[name].usage))
+	}
+	buf.WriteString("  /help\n")
+	return buf.String()
+}
+
+func trustCommand(confirm *Confirm) chatCommand {
+	return chatCommand{
+		usage: "trust [list|revoke <repo|tool|rule>|reset]",
+		run: func(args []string) error {
+			if len(args) == 0 {
+				args = []string{"list"}
+			}
+
+			switch args[0] {
+			case "list":
+				repos, rules := confirm.ListTrusted()
+				if len(repos) == 0 && len(rules) == 0 {
+					fmt.Println("Nothing is trusted")
+					return nil
+				}
+				for _, repo := range repos {
+					fmt.Printf("  repo %s\n", repo)
+				}
+				for _, rule := range rules {
+					if rule.Workspace == "" {
+						fmt.Printf("  always %s\n", rule)
+					} else {
+						fmt.Printf("  always %s (in %s)\n", rule, rule.Workspace)
+					}
+				}
+			case "revoke":
+				removed, err := confirm.Revoke(strings.Join(args[1:], " "))
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Revoked %d trusted entries\n", removed)
+			case "reset":
+				if err := confirm.ResetAll(); err != nil {
+					return err
+				}
+				fmt.Println("Revoked all trusted entries")
+			default:
+				return fmt.Errorf("unknown trust command %q", args[0])
+			}
+			return nil
+		},
+	}
+}
+
+func forkCommand(conv *conversation) chatCommand {
+	return chatCommand{
+		usage: "fork [<turn> [name]]",
+		run: func(args []string) error {
+			if len(args) == 0 {
+				if len(conv.session.Turns) == 0 {
+					fmt.Println("No completed turns to fork from")
+				}
+				for i, turn := range conv.session.Turns {
+					fmt.Printf("  %d  %s  %s\n", i+1, turn.Time.Format(time.TimeOnly), turn.Input)
+				}
+				return nil
+			}
+
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid turn %q", args[0])
+			}
+
+			var name string
+			if len(args) > 1 {
+				name = args[1]
+			}
+			if err := conv.fork(n, name); err != nil {
+				return err
+			}
+			fmt.Printf("Forked %s from turn %d\n", conv.session.Name, n)
+			return nil
+		},
+	}
+}
+
+func branchesCommand(conv *conversation) chatCommand {
+	return chatCommand{
+		usage: "branches",
+		run: func([]string) error {
+			sessions, err := ListSessions(conv.appName, conv.session.Tool)
+			if err != nil {
+				return err
+			}
+			if len(sessions) == 0 {
+				fmt.Println("No saved sessions")
+			}
+			for _, session := range sessions {
+				current := " "
+				if session.Name == conv.session.Name {
+					current = "*"
+				}
+				from := ""
+				if session.Parent != "" {
+					from = " (from " + session.Parent + ")"
+				}
+				fmt.Printf("%s %s%s  %d turns  %s\n", current, session.Name, from, len(session.Turns),
+					session.Updated.Format(time.DateTime))
+			}
+			return nil
+		},
+	}
+}
+
+func switchCommand(conv *conversation) chatCommand {
+	return chatCommand{
+		usage: "switch <name>",
+		run: func(args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("usage: /switch <name>")
+			}
+			if err := conv.switchTo(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Switched to %s\n", conv.session.Name)
+			return nil
+		},
+	}
+}
+
+func undoCommand(conv *conversation, redraw func(transcript []transcriptTurn)) chatCommand {
+	return chatCommand{
+		usage: "undo [files]",
+		run: func(args []string) error {
+			files := len(args) > 0 && args[0] == "files"
+			restored, err := conv.undo(files)
+			if err != nil {
+				return err
+			}
+			redraw(conv.transcript)
+			if files {
+				fmt.Printf("Undid last turn and restored %d files\n", restored)
+			} else {
+				fmt.Println("Undid last turn")
+			}
+			return nil
+		},
+	}
+}
+
+func retryCommand(conv *conversation) chatCommand {
+	return chatCommand{
+		usage: "retry",
+		submit: func([]string) (string, error) {
+			input, err := conv.retry()
+			if err != nil {
+				return "", err
+			}
+			if input == "" {
+				return "", fmt.Errorf("the last turn had no input to retry")
+			}
+			return input, nil
+		},
+	}
+}
+
+func exportCommand(conv *conversation) chatCommand {
+	return chatCommand{
+		usage: "export <file.md|file.html>",
+		run: func(args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("usage: /export <file.md|file.html>")
+			}
+			if err := conv.export(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Exported %d turns to %s\n", len(conv.transcript), args[0])
+			return nil
+		},
+	}
+}
+
+func historyCommand(conv *conversation, ui userInterface, redraw func(transcript []transcriptTurn)) chatCommand {
+	return chatCommand{
+		usage: "history",
+		run: func([]string) error {
+			if len(conv.transcript) == 0 {
+				return fmt.Errorf("no history yet")
+			}
+
+			var content []string
+			for _, turn := range conv.transcript {
+				content = append(content, strings.TrimSuffix(turn.Rendered, "\n"))
+			}
+
+			p := newPager(strings.Join(content, "\n"), pterm.GetTerminalHeight())
+			p.top = p.bottom()
+			p.message = "q to quit, h for help"
+			p.page(os.Stdout, func(text string) (string, bool) {
+				return ui.Ask(text, false, true)
+			})
+			redraw(conv.transcript)
+			return nil
+		},
+	}
+}
+
+func callsCommand(conv *conversation, ui userInterface, redraw func(transcript []transcriptTurn)) chatCommand {
+	return chatCommand{
+		usage: "calls [turn]",
+		run: func(args []string) error {
+			if len(conv.transcript) == 0 {
+				return fmt.Errorf("no calls yet")
+			}
+
+			n := len(conv.transcript)
+			if len(args) > 0 {
+				var err error
+				if n, err = strconv.Atoi(args[0]); err != nil || n < 1 || n > len(conv.transcript) {
+					return fmt.Errorf("turn %s does not exist, there are %d turns", args[0], len(conv.transcript))
+				}
+			}
+
+			tree := newCallTree(conv.transcript[n-1])
+			tree.message = callTreeHelp
+			tree.browse(os.Stdout, func(text string) (string, bool) {
+				return ui.Ask(text, false, true)
+			})
+			redraw(conv.transcript)
+			return nil
+		},
+	}
+}
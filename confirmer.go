@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+// PromptFunc asks the user for a value. False is returned if the user aborted.
+type PromptFunc func(text string, sensitive, allowEmptyResponse bool) (string, bool)
+
+// ConfirmFunc asks the user to confirm an action. False is returned if the user aborted.
+type ConfirmFunc func(text string) (Answer, bool, error)
+
+// Confirmer decides how the confirm and prompt events of a run are answered. Confirm is the default
+// implementation, asking the user in the terminal. Implementations may use the given functions to ask the
+// user or decide on their own. Returning false stops the run.
+type Confirmer interface {
+	ResolveConfirm(ctx context.Context, event gptscript.Frame, prompter ConfirmFunc) (gptscript.AuthResponse, bool, error)
+	ResolvePrompt(ctx context.Context, event gptscript.Frame, prompter PromptFunc) (gptscript.PromptResponse, bool, error)
+}
+
+func isConfirmEvent(event gptscript.Frame) bool {
+	return event.Call != nil && event.Call.Type == gptscript.EventTypeCallConfirm
+}
+
+func isPromptEvent(event gptscript.Frame) bool {
+	return event.Prompt != nil && event.Prompt.Type == gptscript.EventTypePrompt
+}
+
+func handleConfirm(ctx context.Context, client *gptscript.GPTScript, confirmer Confirmer, event gptscript.Frame, prompter ConfirmFunc) (bool, error) {
+	if !isConfirmEvent(event) {
+		return true, nil
+	}
+
+	resp, ok, err := confirmer.ResolveConfirm(ctx, event, prompter)
+	if !ok || err != nil {
+		return ok, err
+	}
+
+	return true, client.Confirm(ctx, resp)
+}
+
+func handlePrompt(ctx context.Context, client *gptscript.GPTScript, confirmer Confirmer, event gptscript.Frame, prompter PromptFunc) (bool, error) {
+	if !isPromptEvent(event) {
+		return true, nil
+	}
+
+	resp, ok, err := confirmer.ResolvePrompt(ctx, event, prompter)
+	if !ok || err != nil {
+		return ok, err
+	}
+
+	return true, client.PromptResponse(ctx, resp)
+}
+
+// WebhookConfirmer answers confirmations and prompts by posting them to a URL, for example a chat bot
+// that asks a reviewer.
+type WebhookConfirmer struct {
+	URL    string
+	Client *http.Client
+}
+
+// WebhookRequest is the body posted to the webhook.
+type WebhookRequest struct {
+	// Type is either "confirm" or "prompt"
+	Type        string                 `json:"type"`
+	ID          string                 `json:"id"`
+	ToolName    string                 `json:"toolName,omitempty"`
+	Instruction string                 `json:"instruction,omitempty"`
+	DisplayText string                 `json:"displayText,omitempty"`
+	Input       map[string]any         `json:"input,omitempty"`
+	Prompt      *gptscript.PromptFrame `json:"prompt,omitempty"`
+}
+
+// WebhookResponse is the body the webhook replies with. Accept and Message answer a confirmation,
+// Responses answers a prompt.
+type WebhookResponse struct {
+	Accept    bool              `json:"accept"`
+	Message   string            `json:"message,omitempty"`
+	Responses map[string]string `json:"responses,omitempty"`
+}
+
+func (w *WebhookConfirmer) ResolveConfirm(ctx context.Context, event gptscript.Frame, _ ConfirmFunc) (gptscript.AuthResponse, bool, error) {
+	resp, err := w.post(ctx, WebhookRequest{
+		Type:        "confirm",
+		ID:          event.Call.ID,
+		ToolName:    first(event.Call.ToolName, event.Call.Tool.Name),
+		Instruction: event.Call.Tool.Instructions,
+		DisplayText: event.Call.DisplayText,
+		Input:       inputArgs(event),
+	})
+	if err != nil {
+		return gptscript.AuthResponse{}, true, err
+	}
+
+	return gptscript.AuthResponse{
+		ID:      event.Call.ID,
+		Accept:  resp.Accept,
+		Message: resp.Message,
+	}, true, nil
+}
+
+func (w *WebhookConfirmer) ResolvePrompt(ctx context.Context, event gptscript.Frame, _ PromptFunc) (gptscript.PromptResponse, bool, error) {
+	if event.Prompt.Sensitive {
+		return gptscript.PromptResponse{}, true, fmt.Errorf("refusing to send sensitive prompt %q to webhook", event.Prompt.Message)
+	}
+
+	resp, err := w.post(ctx, WebhookRequest{
+		Type:   "prompt",
+		ID:     event.Prompt.ID,
+		Prompt: event.Prompt,
+	})
+	if err != nil {
+		return gptscript.PromptResponse{}, true, err
+	}
+
+	return gptscript.PromptResponse{
+		ID:        event.Prompt.ID,
+		Responses: resp.Responses,
+	}, true, nil
+}
+
+func (w *WebhookConfirmer) post(ctx context.Context, body WebhookRequest) (WebhookResponse, error) {
+	var result WebhookResponse
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return result, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(data))
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("webhook %s returned status %d", w.URL, resp.StatusCode)
+	}
+
+	return result, json.NewDecoder(resp.Body).Decode(&result)
+}
@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+func TestWebhookConfirmer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req WebhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		command, _ := req.Input["command"].(string)
+		_ = json.NewEncoder(w).Encode(WebhookResponse{
+			Accept:  req.Type == "confirm" && command == "ls",
+			Message: "reviewed",
+		})
+	}))
+	defer server.Close()
+
+	confirmer := &WebhookConfirmer{URL: server.URL}
+
+	for command, want := range map[string]bool{"ls": true, "rm -rf /": false} {
+		event := sysEvent("#!sys.exec", `{"command": "`+command+`"}`)
+		event.Call.ID = "call-1"
+
+		resp, ok, err := confirmer.ResolveConfirm(context.Background(), event, nil)
+		if err != nil || !ok {
+			t.Fatalf("ResolveConfirm(%q) = %v, %v", command, ok, err)
+		}
+		if resp != (gptscript.AuthResponse{ID: "call-1", Accept: want, Message: "reviewed"}) {
+			t.Errorf("ResolveConfirm(%q) = %+v, want accept %v", command, resp, want)
+		}
+	}
+}
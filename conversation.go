@@ -0,0 +1,219 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+// conversation is the session the chat loop is currently in. Commands that move the conversation to
+// another chat state set pending, and the next input starts a new run from it instead of continuing
+// the current run.
+type conversation struct {
+	appName      string
+	session      Session
+	pending      *string
+	prices       map[string]Price
+	defaultModel string
+	// initial is the chat state before the first turn of the session
+	initial string
+	// failed is set when the last turn ended in an error, failed turns are not recorded
+	failed     bool
+	lastInput  *string
+	transcript []transcriptTurn
+	decisions  map[string]gptscript.AuthResponse
+	journal    *fileJournal
+}
+
+func (c *conversation) restore(chatState string) {
+	c.pending = &chatState
+}
+
+// begin starts tracking the files changed by a new turn.
+func (c *conversation) begin(workspace string) {
+	c.journal = newFileJournal(workspace)
+	c.decisions = map[string]gptscript.AuthResponse{}
+}
+
+// record snapshots the chat state of a completed turn and autosaves named sessions.
+func (c *conversation) record(run *gptscript.Run, input, text string) error {
+	turn := transcriptTurn{
+		Input:     input,
+		Rendered:  text,
+		Calls:     run.Calls(),
+		Decisions: c.decisions,
+		State:     run.State(),
+	}
+	if run.Err() != nil {
+		turn.Error = run.Err().Error()
+	}
+	c.transcript = append(c.transcript, turn)
+	c.lastInput = &input
+	c.failed = false
+
+	switch run.State() {
+	case gptscript.Finished:
+		if c.session.Name != "" {
+			return DeleteSession(c.appName, c.session.Tool, c.session.Name)
+		}
+		return nil
+	case gptscript.Error:
+		// Don't record failed turns, the next input continues from the last good state
+		c.failed = true
+		return nil
+	}
+
+	now := time.Now()
+	c.session.ChatState = run.ChatState()
+	c.session.LastMessage = input
+	c.session.Updated = now
+	c.session.Turns = append(c.session.Turns, Turn{
+		Input:     input,
+		ChatState: c.session.ChatState,
+		Time:      now,
+	})
+	return c.save()
+}
+
+func (c *conversation) save() error {
+	if c.session.Name == "" {
+		return nil
+	}
+	return SaveSession(c.appName, c.session)
+}
+
+// fork starts a new session from the state after turn n (1-based) of the current session.
+func (c *conversation) fork(n int, name string) error {
+	if n < 1 || n > len(c.session.Turns) {
+		return fmt.Errorf("turn %d does not exist, there are %d turns", n, len(c.session.Turns))
+	}
+
+	if name == "" {
+		var err error
+		name, err = c.branchName()
+		if err != nil {
+			return err
+		}
+	} else if _, ok, err := LoadSession(c.appName, c.session.Tool, name); err != nil {
+		return err
+	} else if ok {
+		return fmt.Errorf("session %s already exists", name)
+	}
+
+	turn := c.session.Turns[n-1]
+	branch := Session{
+		Name:        name,
+		Tool:        c.session.Tool,
+		Parent:      c.session.Name,
+		ChatState:   turn.ChatState,
+		LastMessage: turn.Input,
+		Turns:       append([]Turn(nil), c.session.Turns[:n]...),
+		Updated:     time.Now(),
+	}
+	if err := SaveSession(c.appName, branch); err != nil {
+		return err
+	}
+
+	c.session = branch
+	c.restore(branch.ChatState)
+	return nil
+}
+
+func (c *conversation) branchName() (string, error) {
+	base := c.session.Name
+	if base == "" {
+		base = "chat"
+	}
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s-%d", base, i)
+		_, ok, err := LoadSession(c.appName, c.session.Tool, name)
+		if err != nil || !ok {
+			return name, err
+		}
+	}
+}
+
+// switchTo continues the conversation in another saved session of the same tool.
+func (c *conversation) switchTo(name string) error {
+	session, ok, err := LoadSession(c.appName, c.session.Tool, name)
+	if err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("session %s does not exist", name)
+	}
+
+	c.session = session
+	c.restore(session.ChatState)
+	return nil
+}
+
+// chatState returns the chat state after the last recorded turn, or the one the conversation started from.
+func (c *conversation) chatState() string {
+	if len(c.session.Turns) == 0 {
+		return c.initial
+	}
+	return c.session.Turns[len(c.session.Turns)-1].ChatState
+}
+
+// undo goes back to the chat state before the last turn, optionally restoring the files the turn changed.
+// It returns the number of restored files.
+func (c *conversation) undo(files bool) (int, error) {
+	if c.failed {
+		// The failed turn didn't change the chat state, only its transcript and files are undone
+		c.failed = false
+	} else if len(c.session.Turns) == 0 {
+		return 0, fmt.Errorf("nothing to undo")
+	} else {
+		c.session.Turns = c.session.Turns[:len(c.session.Turns)-1]
+		c.session.ChatState = c.chatState()
+		c.session.LastMessage = ""
+		if len(c.session.Turns) > 0 {
+			c.session.LastMessage = c.session.Turns[len(c.session.Turns)-1].Input
+		}
+		c.session.Updated = time.Now()
+		if err := c.save(); err != nil {
+			return 0, err
+		}
+	}
+
+	c.restore(c.chatState())
+	c.lastInput = nil
+	if len(c.transcript) > 0 {
+		c.transcript = c.transcript[:len(c.transcript)-1]
+	}
+
+	journal := c.journal
+	c.journal = nil
+	if !files || journal == nil {
+		return 0, nil
+	}
+	return journal.rollback()
+}
+
+// retry goes back to the chat state before the last turn and returns its input so it can be submitted again.
+func (c *conversation) retry() (string, error) {
+	if c.lastInput == nil {
+		return "", fmt.Errorf("nothing to retry")
+	}
+	input := *c.lastInput
+	if _, err := c.undo(false); err != nil {
+		return "", err
+	}
+	return input, nil
+}
+
+// recordedConfirmer records the confirmation decisions of a turn for the transcript.
+type recordedConfirmer struct {
+	Confirmer
+	conv *conversation
+}
+
+func (r recordedConfirmer) ResolveConfirm(ctx context.Context, event gptscript.Frame, prompter ConfirmFunc) (gptscript.AuthResponse, bool, error) {
+	resp, ok, err := r.Confirmer.ResolveConfirm(ctx, event, prompter)
+	if ok && err == nil && r.conv.decisions != nil {
+		r.conv.decisions[event.Call.ID] = resp
+	}
+	return resp, ok, err
+}
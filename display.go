@@ -1,6 +1,9 @@
 package tui
 
 import (
+	"fmt"
+	"os"
+	"os/signal"
 	"strings"
 	"sync"
 	"time"
@@ -13,6 +16,18 @@ var (
 	loopDelay = 200 * time.Millisecond
 )
 
+// userInterface is how a run shows output and asks the user for input.
+type userInterface interface {
+	Ask(text string, sensitive, allowEmptyResponse bool) (string, bool)
+	AskYesNo(text string) (Answer, bool, error)
+	Prompt(text string) (string, bool)
+	Progress(text func() string)
+	Finished(text string)
+	// Err returns why the user interface could not answer a question, if it could not
+	Err() error
+	Close() error
+}
+
 type displayState struct {
 	area      area
 	lastPrint string
@@ -22,6 +37,9 @@ type displayState struct {
 
 type display struct {
 	displayState
+	status func() string
+	// prompting is set while the prompt for the next turn is shown
+	prompting   bool
 	prompter    *prompter
 	contentLock sync.Mutex
 	paintLock   sync.Mutex
@@ -34,21 +52,58 @@ func newDisplay(tool string) (*display, error) {
 		return nil, err
 	}
 
-	t := time.NewTicker(loopDelay)
+	var (
+		t       = time.NewTicker(loopDelay)
+		resized = make(chan os.Signal, 1)
+		done    = make(chan struct{})
+	)
+	notifyResize(resized)
+
 	d := &display{
 		prompter: prompter,
-		closer:   t.Stop,
+		closer: func() {
+			t.Stop()
+			signal.Stop(resized)
+			close(done)
+		},
 	}
 
 	go func() {
-		for range t.C {
-			d.paint()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				d.paint()
+			case <-resized:
+				d.resize()
+			}
 		}
 	}()
 
 	return d, nil
 }
 
+// resize rebuilds the styles for the new width of the terminal and redraws the output of the current turn.
+func (a *display) resize() {
+	if err := resizeStyles(pterm.GetTerminalWidth()); err != nil {
+		return
+	}
+
+	a.paintLock.Lock()
+	a.contentLock.Lock()
+	if a.lastPrint != "" {
+		// The terminal reflowed the old output so the area can't be updated in place
+		fmt.Print("\033[H\033[2J")
+		a.area = area{}
+		a.lastPrint = ""
+	}
+	a.contentLock.Unlock()
+	a.paintLock.Unlock()
+
+	a.paint()
+}
+
 func (a *display) readline(f func() (string, bool)) (string, bool) {
 	a.paint()
 	a.paintLock.Lock()
@@ -85,6 +140,8 @@ const (
 	Yes    = Answer("Yes")
 	No     = Answer("No")
 	Always = Answer("Always")
+	// AlwaysServer allows every tool of an MCP server
+	AlwaysServer = Answer("AlwaysServer")
 )
 
 func (a *display) AskYesNo(text string) (Answer, bool, error) {
@@ -94,22 +151,47 @@ func (a *display) AskYesNo(text string) (Answer, bool, error) {
 		if !ok {
 			return No, ok, nil
 		}
-		switch strings.ToLower(strings.TrimSpace(line)) {
-		case "y", "yes":
-			return Yes, true, nil
-		case "n", "no":
-			return No, true, nil
-		case "a", "always":
-			return Always, true, nil
+		if answer, ok := parseAnswer(line); ok {
+			return answer, true, nil
 		}
 	}
 }
 
+func parseAnswer(line string) (Answer, bool) {
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return Yes, true
+	case "n", "no":
+		return No, true
+	case "a", "always":
+		return Always, true
+	case "s", "server":
+		return AlwaysServer, true
+	}
+	return "", false
+}
+
 func (a *display) Prompt(text string) (string, bool) {
 	a.prompter.SetPrompt(text)
+	a.setPrompting(true)
+	defer a.setPrompting(false)
 	return a.readline(a.prompter.Readline(false))
 }
 
+// setPrompting shows the status bar above the prompt between turns, or removes it when the prompt returns.
+func (a *display) setPrompting(prompting bool) {
+	a.paintLock.Lock()
+	defer a.paintLock.Unlock()
+	a.contentLock.Lock()
+	defer a.contentLock.Unlock()
+
+	a.prompting = prompting
+	if !prompting && a.content == nil && a.lastPrint != "" {
+		a.area.Update("")
+		a.displayState = displayState{}
+	}
+}
+
 func (a *display) getContent() string {
 	if a.content == nil {
 		return ""
@@ -130,19 +212,29 @@ func (a *display) paint() {
 	}
 
 	newContent := a.getContent()
+	status := a.status
+	showStatus := newContent != "" || a.prompting
 	a.contentLock.Unlock()
 
-	if newContent == a.lastPrint {
-		return
+	// The status bar is not part of the finished output of a turn so it's not left behind in the scrollback.
+	// Between turns it is only shown above the prompt, commands print where it was.
+	var statusLine string
+	if status != nil && showStatus {
+		statusLine = status()
 	}
 
-	lines := strings.Split(newContent, "\n")
-	height := pterm.GetTerminalHeight()
-	if len(lines) > height {
-		lines = lines[len(lines)-height:]
+	if newContent+statusLine == a.lastPrint {
+		return
 	}
-	a.area.Update(strings.Join(lines, "\n"))
-	a.lastPrint = newContent
+
+	a.area.Update(withStatus(newContent, statusLine, pterm.GetTerminalHeight()))
+	a.lastPrint = newContent + statusLine
+}
+
+func (a *display) SetStatus(status func() string) {
+	a.contentLock.Lock()
+	defer a.contentLock.Unlock()
+	a.status = status
 }
 
 func (a *display) Progress(text func() string) {
@@ -151,6 +243,10 @@ func (a *display) Progress(text func() string) {
 	a.content = text
 }
 
+func (a *display) Err() error {
+	return nil
+}
+
 func (a *display) Close() error {
 	a.closer()
 	return a.prompter.Close()
@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+type EventLogOptions struct {
+	// MaxSize rotates the log once it would grow beyond this many bytes, 0 disables size based rotation
+	MaxSize int64
+	// MaxFiles is the number of rotated logs kept next to the log, defaults to 5
+	MaxFiles int
+	// PerSession writes every run to a new log named after EventLog with the start time appended
+	PerSession bool
+	// Types only logs events of these types. By default every event except progress is logged.
+	Types []gptscript.EventType
+	// SecretPatterns are regular expressions of values to redact in addition to DefaultSecretPatterns
+	SecretPatterns []string
+}
+
+// DefaultSecretPatterns match common API keys and tokens, they are always redacted from the event log.
+var DefaultSecretPatterns = []string{
+	`sk-[A-Za-z0-9_-]{20,}`,
+	`gh[pousr]_[A-Za-z0-9]{30,}`,
+	`github_pat_[A-Za-z0-9_]{30,}`,
+	`AKIA[0-9A-Z]{16}`,
+	`xox[abprs]-[A-Za-z0-9-]{10,}`,
+	`(?i)bearer\s+[A-Za-z0-9._~+/-]{16,}=*`,
+}
+
+// eventLogger appends events to the event log with secrets redacted.
+type eventLogger struct {
+	file      string
+	f         *os.File
+	size      int64
+	opts      EventLogOptions
+	patterns  []*regexp.Regexp
+	sensitive map[string]struct{}
+}
+
+func newEventLogger(file string, opts *EventLogOptions) (*eventLogger, error) {
+	l := &eventLogger{
+		file:      file,
+		sensitive: map[string]struct{}{},
+	}
+	if opts != nil {
+		l.opts = *opts
+	}
+	if l.opts.MaxFiles <= 0 {
+		l.opts.MaxFiles = 5
+	}
+
+	for _, pattern := range append(slices.Clone(DefaultSecretPatterns), l.opts.SecretPatterns...) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret pattern %q: %w", pattern, err)
+		}
+		l.patterns = append(l.patterns, re)
+	}
+
+	if l.opts.PerSession {
+		ext := filepath.Ext(file)
+		l.file = fmt.Sprintf("%s-%s%s", strings.TrimSuffix(file, ext), time.Now().Format("20060102T150405"), ext)
+	}
+
+	return l, l.open()
+}
+
+func (l *eventLogger) open() error {
+	f, err := os.OpenFile(l.file, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.f = f
+	l.size = info.Size()
+	return nil
+}
+
+// rotate moves the log to file.1, file.1 to file.2 and so on, dropping the oldest.
+func (l *eventLogger) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	for i := l.opts.MaxFiles - 1; i > 0; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", l.file, i), fmt.Sprintf("%s.%d", l.file, i+1))
+	}
+	if err := os.Rename(l.file, l.file+".1"); err != nil {
+		return err
+	}
+	return l.open()
+}
+
+func eventType(event gptscript.Frame) gptscript.EventType {
+	switch {
+	case event.Call != nil:
+		return event.Call.Type
+	case event.Run != nil:
+		return event.Run.Type
+	case event.Prompt != nil:
+		return event.Prompt.Type
+	}
+	return ""
+}
+
+func (l *eventLogger) Log(event gptscript.Frame) error {
+	typ := eventType(event)
+	if len(l.opts.Types) > 0 {
+		if !slices.Contains(l.opts.Types, typ) {
+			return nil
+		}
+	} else if typ == gptscript.EventTypeCallProgress {
+		return nil
+	}
+
+	event, err := l.redact(event)
+	if err != nil {
+		return fmt.Errorf("failed to redact %s event, not logging it: %w", typ, err)
+	}
+
+	data, err := json.Marshal(eventRecord{
+		Time:  time.Now(),
+		Event: event,
+	})
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if l.opts.MaxSize > 0 && l.size > 0 && l.size+int64(len(data)) > l.opts.MaxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.f.Write(data)
+	l.size += int64(n)
+	return err
+}
+
+// redact removes the output of credential tools and prompts, values of sensitive prompt fields and
+// anything matching a secret pattern from the event. The event is never returned unredacted, if redacting
+// fails an error is returned instead.
+func (l *eventLogger) redact(event gptscript.Frame) (gptscript.Frame, error) {
+	if event.Prompt != nil {
+		for _, field := range event.Prompt.Fields {
+			if event.Prompt.Sensitive || (field.Sensitive != nil && *field.Sensitive) {
+				l.sensitive[field.Name] = struct{}{}
+			}
+		}
+	}
+
+	if event.Call != nil {
+		call := *event.Call
+		_, isPrompt := isSysTool(event, "prompt")
+		if call.ToolCategory == gptscript.CredentialToolCategory || isPrompt {
+			call.Output = slices.Clone(call.Output)
+			for i := range call.Output {
+				call.Output[i].Content = redacted
+			}
+		}
+		if call.ToolCategory == gptscript.CredentialToolCategory {
+			call.Input = redacted
+		}
+		event.Call = &call
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return gptscript.Frame{}, err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return gptscript.Frame{}, err
+	}
+	data, err = json.Marshal(redactValue(generic, l.sensitive, l.patterns))
+	if err != nil {
+		return gptscript.Frame{}, err
+	}
+
+	var result gptscript.Frame
+	if err := json.Unmarshal(data, &result); err != nil {
+		return gptscript.Frame{}, err
+	}
+	return result, nil
+}
+
+func (l *eventLogger) Close() error {
+	return l.f.Close()
+}
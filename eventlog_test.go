@@ -0,0 +1,173 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+func TestEventLoggerRedact(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "events.jsonl")
+	l, err := newEventLogger(file, &EventLogOptions{
+		SecretPatterns: []string{`hunter\d`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sensitive := true
+	events := []gptscript.Frame{
+		{Prompt: &gptscript.PromptFrame{
+			Type:   gptscript.EventTypePrompt,
+			Prompt: gptscript.Prompt{Fields: gptscript.Fields{{Name: "password", Sensitive: &sensitive}}},
+		}},
+		{Call: &gptscript.CallFrame{
+			Type:  gptscript.EventTypeCallFinish,
+			Input: `{"password": "open sesame", "user": "bob"}`,
+		}},
+		{Call: &gptscript.CallFrame{
+			Type:   gptscript.EventTypeCallFinish,
+			Output: []gptscript.Output{{Content: "key sk-abcdefghijklmnopqrstuvwxyz and hunter2"}},
+		}},
+		{Call: &gptscript.CallFrame{
+			CallContext: gptscript.CallContext{ToolCategory: gptscript.CredentialToolCategory},
+			Type:        gptscript.EventTypeCallFinish,
+			Output:      []gptscript.Output{{Content: `{"env": {"TOKEN": "abc"}}`}},
+		}},
+		{Call: &gptscript.CallFrame{Type: gptscript.EventTypeCallProgress}},
+	}
+	for _, event := range events {
+		if err := l.Log(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+
+	for _, secret := range []string{"open sesame", "sk-abc", "hunter2", `\"TOKEN\"`, string(gptscript.EventTypeCallProgress)} {
+		if strings.Contains(log, secret) {
+			t.Errorf("event log contains %q:\n%s", secret, log)
+		}
+	}
+	if !strings.Contains(log, "bob") {
+		t.Errorf("event log is missing non sensitive values:\n%s", log)
+	}
+}
+
+func TestEventLoggerRotate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "events.jsonl")
+	l, err := newEventLogger(file, &EventLogOptions{
+		MaxSize:  1000,
+		MaxFiles: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	for i := 0; i < 20; i++ {
+		if err := l.Log(gptscript.Frame{Call: &gptscript.CallFrame{Type: gptscript.EventTypeCallFinish}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{file, file + ".1", file + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > 1000 {
+			t.Errorf("%s is %d bytes, want at most 1000", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(file + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected %s.3 to not exist", file)
+	}
+}
+
+func TestEventLoggerRedactStructuralNames(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "events.jsonl")
+	l, err := newEventLogger(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sensitive := true
+	events := []gptscript.Frame{
+		{Prompt: &gptscript.PromptFrame{
+			Type: gptscript.EventTypePrompt,
+			Prompt: gptscript.Prompt{Fields: gptscript.Fields{
+				{Name: "start", Sensitive: &sensitive},
+				{Name: "type", Sensitive: &sensitive},
+			}},
+		}},
+		{Call: &gptscript.CallFrame{
+			Type:   gptscript.EventTypeCallFinish,
+			Start:  time.Now(),
+			Input:  `{"start": "open sesame"}`,
+			Output: []gptscript.Output{{Content: "key sk-abcdefghijklmnopqrstuvwxyz"}},
+		}},
+	}
+	for _, event := range events {
+		if err := l.Log(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	for _, secret := range []string{"open sesame", "sk-abc"} {
+		if strings.Contains(log, secret) {
+			t.Errorf("event log contains %q:\n%s", secret, log)
+		}
+	}
+	if !strings.Contains(log, `"type":"callFinish"`) {
+		t.Errorf("expected structural fields to be kept:\n%s", log)
+	}
+}
+
+func TestEventLoggerRedactFailsClosed(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "events.jsonl")
+	// Redacting the digits of the start time makes the event invalid
+	l, err := newEventLogger(file, &EventLogOptions{SecretPatterns: []string{`\d{4}`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = l.Log(gptscript.Frame{Call: &gptscript.CallFrame{
+		Type:   gptscript.EventTypeCallFinish,
+		Start:  time.Now(),
+		Output: []gptscript.Output{{Content: "pin 1234"}},
+	}})
+	if err == nil {
+		t.Fatal("expected an error when the event can't be redacted")
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "1234") {
+		t.Fatalf("unredacted event was logged:\n%s", data)
+	}
+}
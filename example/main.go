@@ -2,33 +2,59 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"time"
 
 	"github.com/gptscript-ai/tui"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		log.Fatal("usage: " + os.Args[0] + " [TOOL NAME]")
+	session := flag.String("session", "", "Name of the chat session to start or resume")
+	listSessions := flag.Bool("list-sessions", false, "List the saved sessions of the tool")
+	deleteSession := flag.String("delete-session", "", "Delete the named session of the tool")
+	themeName := flag.String("theme", tui.ThemeDefault, fmt.Sprintf("Theme of the output, one of %v", tui.ThemeNames()))
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		log.Fatal("usage: " + os.Args[0] + " [--session NAME] [--list-sessions] [--delete-session NAME] [--theme NAME] [TOOL NAME]")
 	}
+	tool := flag.Arg(0)
 
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
-	defer cancel()
+	switch {
+	case *listSessions:
+		sessions, err := tui.ListSessions("", tool)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range sessions {
+			fmt.Printf("%s\t%s\t%s\n", s.Name, s.Updated.Format(time.DateTime), s.LastMessage)
+		}
+		return
+	case *deleteSession != "":
+		if err := tui.DeleteSession("", tool, *deleteSession); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	var state string
-	data, err := os.ReadFile("state.json")
-	if err == nil {
-		state = string(data)
+	theme, err := tui.BuiltinTheme(*themeName)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	if err := tui.Run(ctx, os.Args[1], tui.RunOptions{
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := tui.Run(ctx, tool, tui.RunOptions{
 		Workspace:           "./workspace",
 		TrustedRepoPrefixes: []string{"github.com/gptscript-ai/context"},
 		DisableCache:        true,
-		SaveChatStateFile:   "state.json",
-		ChatState:           state,
+		Session:             *session,
+		Theme:               theme,
 	}); err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,164 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"golang.org/x/exp/maps"
+)
+
+// transcriptTurn is a turn of the conversation as it is exported.
+type transcriptTurn struct {
+	Input     string
+	Rendered  string
+	Calls     gptscript.CallFrames
+	Decisions map[string]gptscript.AuthResponse
+	State     gptscript.RunState
+	Error     string
+}
+
+// export writes the conversation so far to file, as HTML if the file ends in .html or .htm and as
+// Markdown otherwise.
+func (c *conversation) export(file string) error {
+	data := transcriptMarkdown(c.session.Tool, c.transcript, c.prices, c.defaultModel)
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".html", ".htm":
+		var err error
+		data, err = transcriptHTML(c.session.Tool, data)
+		if err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(file, data, 0600)
+}
+
+func transcriptMarkdown(tool string, turns []transcriptTurn, prices map[string]Price, defaultModel string) []byte {
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "# %s\n\n_Exported %s_\n", tool, time.Now().Format(time.RFC1123))
+
+	var total usage
+	for i, turn := range turns {
+		fmt.Fprintf(buf, "\n## Turn %d\n\n", i+1)
+		if turn.Input != "" {
+			buf.WriteString(quote(turn.Input))
+			buf.WriteString("\n\n")
+		}
+		if len(turn.Calls) > 0 {
+			exportCall(buf, turn, turn.Calls.ParentCallFrame(), nil)
+		}
+		if turn.Error != "" {
+			fmt.Fprintf(buf, "**Error:** %s\n\n", turn.Error)
+		}
+		for _, call := range turn.Calls {
+			total.add(call, prices, defaultModel)
+		}
+		if line := turnUsage(turn.Calls, prices, defaultModel).String(); line != "" {
+			fmt.Fprintf(buf, "_%s_\n\n", line)
+		}
+	}
+
+	if line := total.String(); line != "" {
+		fmt.Fprintf(buf, "\n---\n\n**Total** %s\n", line)
+	}
+
+	return []byte(buf.String())
+}
+
+// exportCall writes a call and its subcalls as Markdown, walking the same tree as printCall.
+func exportCall(buf *strings.Builder, turn transcriptTurn, call gptscript.CallFrame, stack []string) {
+	if slices.Contains(stack, call.ID) {
+		return
+	}
+
+	if resp, ok := turn.Decisions[call.ID]; ok {
+		if resp.Accept {
+			fmt.Fprintf(buf, "**Allowed** `%s`\n\n", call.ToolName)
+		} else if resp.Message != "" {
+			fmt.Fprintf(buf, "**Denied** `%s`: %s\n\n", call.ToolName, resp.Message)
+		} else {
+			fmt.Fprintf(buf, "**Denied** `%s`\n\n", call.ToolName)
+		}
+	}
+
+	if call.DisplayText != "" {
+		buf.WriteString(strings.TrimSpace(call.DisplayText))
+		buf.WriteString("\n\n")
+	}
+
+	for _, output := range call.Output {
+		content, toolCall, _ := strings.Cut(output.Content, ToolCallHeader)
+		if content = strings.TrimSpace(content); content != "" {
+			if strings.HasPrefix(call.Tool.Instructions, "#!") {
+				buf.WriteString(fence("", content))
+			} else {
+				buf.WriteString(content)
+				buf.WriteString("\n\n")
+			}
+		}
+
+		for _, line := range strings.Split(toolCall, "\n") {
+			name, args, ok := strings.Cut(strings.TrimPrefix(line, ToolCallHeader), " -> ")
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(buf, "**Tool call** `%s`\n\n", strings.TrimSpace(name))
+			buf.WriteString(fence("json", args))
+		}
+
+		keys := maps.Keys(output.SubCalls)
+		sort.Slice(keys, func(i, j int) bool {
+			return turn.Calls[keys[i]].Start.Before(turn.Calls[keys[j]].Start)
+		})
+
+		for _, key := range keys {
+			if subCall, ok := turn.Calls[key]; ok {
+				exportCall(buf, turn, subCall, append(stack, call.ID))
+			}
+		}
+	}
+}
+
+func quote(s string) string {
+	return "> " + strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n> ")
+}
+
+// fence wraps content in a code block, with a fence longer than any backtick run in content.
+func fence(lang, content string) string {
+	marker := "```"
+	for strings.Contains(content, marker) {
+		marker += "`"
+	}
+	return marker + lang + "\n" + strings.TrimSpace(content) + "\n" + marker + "\n\n"
+}
+
+const transcriptStyle = `body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Helvetica,Arial,sans-serif;max-width:60em;margin:2em auto;padding:0 1em;line-height:1.5;color:#1f2328}
+h2{border-bottom:1px solid #d1d9e0;padding-bottom:.3em}
+blockquote{margin:0;padding:.5em 1em;border-left:.25em solid #2da44e;background:#f6f8fa}
+pre{background:#f6f8fa;padding:1em;overflow:auto;border-radius:6px}
+code{font-family:ui-monospace,SFMono-Regular,Menlo,monospace;font-size:90%}
+table{border-collapse:collapse}td,th{border:1px solid #d1d9e0;padding:.3em .6em}`
+
+// transcriptHTML converts the Markdown transcript to a self-contained HTML page.
+func transcriptHTML(tool string, markdown []byte) ([]byte, error) {
+	body := &bytes.Buffer{}
+	if err := goldmark.New(goldmark.WithExtensions(extension.GFM)).Convert(markdown, body); err != nil {
+		return nil, fmt.Errorf("failed to render transcript: %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n",
+		html.EscapeString(tool), transcriptStyle)
+	buf.Write(body.Bytes())
+	buf.WriteString("</body>\n</html>\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+func TestExportTranscript(t *testing.T) {
+	start := time.Now()
+	calls := gptscript.CallFrames{
+		"parent": {
+			CallContext: gptscript.CallContext{ID: "parent"},
+			Start:       start,
+			Output: []gptscript.Output{{
+				Content:  "Listing files" + ToolCallHeader + "ls -> {\"dir\": \".\"}",
+				SubCalls: map[string]gptscript.Call{"child": {}},
+			}},
+		},
+		"child": {
+			CallContext: gptscript.CallContext{
+				ID:       "child",
+				ParentID: "parent",
+				ToolName: "ls",
+				Tool:     gptscript.Tool{ToolDef: gptscript.ToolDef{Instructions: "#!sys.ls"}},
+			},
+			Start:  start.Add(time.Second),
+			Output: []gptscript.Output{{Content: "README.md"}},
+		},
+	}
+
+	conv := &conversation{
+		session: Session{Tool: "tool.gpt"},
+		transcript: []transcriptTurn{{
+			Input:     "what files\nare there?",
+			Calls:     calls,
+			Decisions: map[string]gptscript.AuthResponse{"child": {Accept: true}},
+		}, {
+			Input: "<script>",
+			Error: "rate limited",
+		}},
+	}
+
+	dir := t.TempDir()
+	if err := conv.export(filepath.Join(dir, "transcript.md")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "transcript.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# tool.gpt",
+		"## Turn 1",
+		"> what files\n> are there?",
+		"Listing files",
+		"**Tool call** `ls`\n\n```json\n{\"dir\": \".\"}\n```",
+		"**Allowed** `ls`",
+		"```\nREADME.md\n```",
+		"## Turn 2",
+		"**Error:** rate limited",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, data)
+		}
+	}
+
+	if err := conv.export(filepath.Join(dir, "transcript.html")); err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "transcript.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "<h2>Turn 1</h2>") || strings.Contains(string(data), "<script>") {
+		t.Errorf("unexpected html:\n%s", data)
+	}
+}
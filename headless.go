@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/pterm/pterm"
+	"golang.org/x/term"
+)
+
+// headlessDisplay streams output as plain text, one complete line at a time, without any cursor control.
+// It can not ask the user anything, so prompts and confirmations that reach it fail the run.
+type headlessDisplay struct {
+	out  io.Writer
+	lock sync.Mutex
+	// started is set once the input of the turn is printed
+	started bool
+	// text is the output of the turn so far and printed the part of it that was printed, complete lines only
+	text    string
+	printed string
+	usage   string
+	err     error
+}
+
+// callStreamer is implemented by user interfaces that print the text of a turn from its calls as it grows
+// instead of painting the rendered text, which changes as it is rendered again.
+type callStreamer interface {
+	StreamCalls(input string, calls gptscript.CallFrames, usage string)
+}
+
+func newHeadlessDisplay(out io.Writer) *headlessDisplay {
+	return &headlessDisplay{
+		out: out,
+	}
+}
+
+func (h *headlessDisplay) StreamCalls(input string, calls gptscript.CallFrames, usage string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if !h.started {
+		h.started = true
+		if input != "" {
+			_, _ = fmt.Fprintf(h.out, "> %s\n\n", input)
+		}
+	}
+	h.usage = usage
+
+	h.text = callText(calls.ParentCallFrame())
+	if !strings.HasPrefix(h.text, h.printed) {
+		// Earlier output was replaced, continue from the first line that changed
+		h.printed = h.printed[:commonLinesLength(h.printed, h.text)]
+	}
+
+	// The last line may still be growing
+	if end := strings.LastIndex(h.text, "\n") + 1; end > len(h.printed) {
+		_, _ = fmt.Fprint(h.out, h.text[len(h.printed):end])
+		h.printed = h.text[:end]
+	}
+}
+
+// commonLinesLength returns the length of the complete lines a and b start with.
+func commonLinesLength(a, b string) int {
+	var length int
+	for i := 0; i < len(a) && i < len(b) && a[i] == b[i]; i++ {
+		if a[i] == '\n' {
+			length = i + 1
+		}
+	}
+	return length
+}
+
+// Progress does nothing, the text is streamed from the calls by StreamCalls.
+func (h *headlessDisplay) Progress(func() string) {}
+
+// Finished prints the rest of the output of the turn and its usage. The rendered text is not printed.
+func (h *headlessDisplay) Finished(string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if rest := strings.TrimRight(strings.TrimPrefix(h.text, h.printed), "\n"); rest != "" {
+		_, _ = fmt.Fprintln(h.out, rest)
+	}
+	if h.usage != "" {
+		_, _ = fmt.Fprintf(h.out, "\n%s\n", h.usage)
+	}
+
+	h.started = false
+	h.text = ""
+	h.printed = ""
+	h.usage = ""
+}
+
+func (h *headlessDisplay) fail(err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.err == nil {
+		h.err = err
+	}
+}
+
+func (h *headlessDisplay) Ask(text string, _, _ bool) (string, bool) {
+	h.fail(fmt.Errorf("input required in headless mode: %s", strings.TrimSpace(pterm.RemoveColorFromString(text))))
+	return "", false
+}
+
+func (h *headlessDisplay) AskYesNo(text string) (Answer, bool, error) {
+	err := fmt.Errorf("confirmation required in headless mode, allow it with a policy: %s",
+		strings.TrimSpace(pterm.RemoveColorFromString(text)))
+	h.fail(err)
+	return No, true, err
+}
+
+// Prompt ends the conversation, a headless run only runs a single turn.
+func (h *headlessDisplay) Prompt(string) (string, bool) {
+	h.fail(fmt.Errorf("input required in headless mode, use the Input option"))
+	return "", false
+}
+
+func (h *headlessDisplay) Err() error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.err
+}
+
+func (h *headlessDisplay) Close() error {
+	return nil
+}
+
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
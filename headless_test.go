@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+func TestHeadlessDisplayStreamsCalls(t *testing.T) {
+	var (
+		out = &bytes.Buffer{}
+		h   = newHeadlessDisplay(out)
+	)
+
+	stream := func(content string) {
+		h.StreamCalls("hi", gptscript.CallFrames{
+			"1": {
+				CallContext: gptscript.CallContext{ID: "1"},
+				Output:      []gptscript.Output{{Content: content}},
+			},
+		}, "Tokens: 10")
+	}
+
+	// A growing line is only printed once it is complete
+	stream("Hello wor")
+	stream("Hello world, this is the full line\nsecond")
+	// A tool call is not part of the text, so it can't disappear from what was printed
+	stream("Hello world, this is the full line\nsecond line\n" + ToolCallHeader + "ls -> {}")
+	stream("Hello world, this is the full line\nsecond line\nfinal answer")
+	h.Finished("rendered text that is not printed")
+
+	expected := "> hi\n\nHello world, this is the full line\nsecond line\nfinal answer\n\nTokens: 10\n"
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	// Replaced text is printed again from the first line that changed
+	out.Reset()
+	stream("Intro\nfirst draft\n")
+	stream("Intro\nrewritten\nfinal answer")
+	h.Finished("")
+
+	expected = "> hi\n\nIntro\nfirst draft\nrewritten\nfinal answer\n\nTokens: 10\n"
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+type fileBackup struct {
+	exists bool
+	data   []byte
+	mode   fs.FileMode
+}
+
+// fileJournal keeps the original content of the workspace files a turn is about to change so the turn can
+// be undone. Files are backed up when the call is confirmed, which happens before the tool runs.
+type fileJournal struct {
+	workspace string
+	files     map[string]fileBackup
+}
+
+func newFileJournal(workspace string) *fileJournal {
+	return &fileJournal{
+		workspace: resolvePath(workspace),
+		files:     map[string]fileBackup{},
+	}
+}
+
+func (j *fileJournal) track(event gptscript.Frame) {
+	if j == nil || !isConfirmEvent(event) {
+		return
+	}
+
+	var changes bool
+	for _, name := range []string{"write", "append", "remove"} {
+		if _, ok := isSysTool(event, name); ok {
+			changes = true
+		}
+	}
+	if !changes {
+		return
+	}
+
+	path := pathArg(inputArgs(event))
+	if path == "" {
+		return
+	}
+
+	resolved := resolvePath(path)
+	if withinDir(resolved, j.workspace) {
+		j.backup(resolved)
+	}
+}
+
+func (j *fileJournal) backup(path string) {
+	if _, ok := j.files[path]; ok {
+		return
+	}
+
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		j.files[path] = fileBackup{}
+		return
+	} else if err != nil {
+		return
+	}
+
+	if info.IsDir() {
+		_ = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				j.backup(p)
+			}
+			return nil
+		})
+		return
+	}
+
+	if !info.Mode().IsRegular() {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	j.files[path] = fileBackup{
+		exists: true,
+		data:   data,
+		mode:   info.Mode().Perm(),
+	}
+}
+
+// rollback restores the backed up files and removes the ones that did not exist, returning how many
+// files were restored.
+func (j *fileJournal) rollback() (int, error) {
+	var (
+		restored int
+		errs     []error
+	)
+	for path, backup := range j.files {
+		if !backup.exists {
+			if err := os.Remove(path); err == nil {
+				restored++
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.WriteFile(path, backup.data, backup.mode); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		restored++
+	}
+
+	j.files = map[string]fileBackup{}
+	return restored, errors.Join(errs...)
+}
@@ -0,0 +1,292 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/pterm/pterm"
+)
+
+// RecordType is the type of a line of the JSONL output of Run.
+type RecordType string
+
+const (
+	// RecordInput is user input sent to the model, Text is set
+	RecordInput = RecordType("input")
+	// RecordText is new text output of a call, Text is appended to the previous text of CallID unless
+	// Replace is set, in which case it replaces it
+	RecordText = RecordType("text")
+	// RecordCallStart is a call starting, CallID, ParentCallID, ToolName and Input are set
+	RecordCallStart = RecordType("callStart")
+	// RecordCallEnd is a call finishing, CallID, ToolName and Output are set
+	RecordCallEnd = RecordType("callEnd")
+	// RecordConfirmRequested is a call waiting for confirmation, CallID, ToolName and Input are set
+	RecordConfirmRequested = RecordType("confirmRequested")
+	// RecordConfirmResolved is the answer to a confirmation, CallID, Accepted and Message are set
+	RecordConfirmResolved = RecordType("confirmResolved")
+	// RecordAsk is a question the next line of stdin answers. Kind is "confirm", "prompt" or "input".
+	// Message is the question and Sensitive is set if the answer is a secret.
+	RecordAsk = RecordType("ask")
+	// RecordPromptResolved is a prompt from a tool being answered, ID is set. The values are not included.
+	RecordPromptResolved = RecordType("promptResolved")
+	// RecordResult ends a turn, Text is the output and State the state of the run
+	RecordResult = RecordType("result")
+	// RecordError ends a turn that failed, Error is set
+	RecordError = RecordType("error")
+)
+
+// Record is a line of the JSONL output of Run, see RecordType for the fields set for each type.
+type Record struct {
+	Type         RecordType `json:"type"`
+	Time         time.Time  `json:"time"`
+	ID           string     `json:"id,omitempty"`
+	CallID       string     `json:"callID,omitempty"`
+	ParentCallID string     `json:"parentCallID,omitempty"`
+	ToolName     string     `json:"toolName,omitempty"`
+	Text         string     `json:"text,omitempty"`
+	Replace      bool       `json:"replace,omitempty"`
+	Input        string     `json:"input,omitempty"`
+	Output       string     `json:"output,omitempty"`
+	Kind         string     `json:"kind,omitempty"`
+	Message      string     `json:"message,omitempty"`
+	Sensitive    bool       `json:"sensitive,omitempty"`
+	Accepted     *bool      `json:"accepted,omitempty"`
+	State        string     `json:"state,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// runObserver is implemented by user interfaces that report the events of a run instead of rendering them.
+type runObserver interface {
+	Input(text string)
+	Event(event gptscript.Frame)
+	ConfirmResolved(resp gptscript.AuthResponse)
+	PromptResolved(resp gptscript.PromptResponse)
+	Done(run *gptscript.Run)
+}
+
+// observedConfirmer reports the decisions of a Confirmer to an observer.
+type observedConfirmer struct {
+	Confirmer
+	observer runObserver
+}
+
+func (o observedConfirmer) ResolveConfirm(ctx context.Context, event gptscript.Frame, prompter ConfirmFunc) (gptscript.AuthResponse, bool, error) {
+	resp, ok, err := o.Confirmer.ResolveConfirm(ctx, event, prompter)
+	if ok && err == nil {
+		o.observer.ConfirmResolved(resp)
+	}
+	return resp, ok, err
+}
+
+func (o observedConfirmer) ResolvePrompt(ctx context.Context, event gptscript.Frame, prompter PromptFunc) (gptscript.PromptResponse, bool, error) {
+	resp, ok, err := o.Confirmer.ResolvePrompt(ctx, event, prompter)
+	if ok && err == nil {
+		o.observer.PromptResolved(resp)
+	}
+	return resp, ok, err
+}
+
+// jsonDisplay writes a Record per line to out and reads answers to questions one line at a time from in.
+type jsonDisplay struct {
+	lock  sync.Mutex
+	out   *json.Encoder
+	in    *bufio.Scanner
+	texts map[string]string
+}
+
+func newJSONDisplay(in io.Reader, out io.Writer) *jsonDisplay {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &jsonDisplay{
+		out:   json.NewEncoder(out),
+		in:    scanner,
+		texts: map[string]string{},
+	}
+}
+
+func (j *jsonDisplay) write(record Record) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	record.Time = time.Now()
+	_ = j.out.Encode(record)
+}
+
+// readLine reads the next line of input. A line that is a JSON string is decoded so answers can contain
+// newlines.
+func (j *jsonDisplay) readLine(kind, message string, sensitive bool) (string, bool) {
+	j.write(Record{
+		Type:      RecordAsk,
+		Kind:      kind,
+		Message:   pterm.RemoveColorFromString(message),
+		Sensitive: sensitive,
+	})
+	if !j.in.Scan() {
+		return "", false
+	}
+
+	line := j.in.Text()
+	var s string
+	if strings.HasPrefix(line, `"`) && json.Unmarshal([]byte(line), &s) == nil {
+		return s, true
+	}
+	return strings.TrimSpace(line), true
+}
+
+func (j *jsonDisplay) Ask(text string, sensitive, _ bool) (string, bool) {
+	return j.readLine("prompt", text, sensitive)
+}
+
+func (j *jsonDisplay) AskYesNo(text string) (Answer, bool, error) {
+	for {
+		line, ok := j.readLine("confirm", text, false)
+		if !ok {
+			return No, ok, nil
+		}
+		if answer, ok := parseAnswer(line); ok {
+			return answer, true, nil
+		}
+	}
+}
+
+func (j *jsonDisplay) Prompt(text string) (string, bool) {
+	return j.readLine("input", text, false)
+}
+
+func (j *jsonDisplay) Progress(func() string) {}
+
+func (j *jsonDisplay) Finished(string) {}
+
+func (j *jsonDisplay) Err() error {
+	return nil
+}
+
+func (j *jsonDisplay) Close() error {
+	return nil
+}
+
+func (j *jsonDisplay) Input(text string) {
+	j.write(Record{
+		Type: RecordInput,
+		Text: text,
+	})
+}
+
+func (j *jsonDisplay) Event(event gptscript.Frame) {
+	if event.Call == nil {
+		return
+	}
+
+	call := event.Call
+	toolName := first(call.ToolName, call.Tool.Name)
+
+	switch call.Type {
+	case gptscript.EventTypeCallStart:
+		j.write(Record{
+			Type:         RecordCallStart,
+			CallID:       call.ID,
+			ParentCallID: call.ParentID,
+			ToolName:     toolName,
+			Input:        call.Input,
+		})
+	case gptscript.EventTypeCallConfirm:
+		j.write(Record{
+			Type:     RecordConfirmRequested,
+			CallID:   call.ID,
+			ToolName: toolName,
+			Input:    call.Input,
+		})
+	}
+
+	j.text(*call)
+
+	if call.Type == gptscript.EventTypeCallFinish {
+		j.write(Record{
+			Type:     RecordCallEnd,
+			CallID:   call.ID,
+			ToolName: toolName,
+			Output:   callText(*call),
+		})
+	}
+}
+
+// text writes the text a call has output since the last event.
+func (j *jsonDisplay) text(call gptscript.CallFrame) {
+	text := callText(call)
+
+	j.lock.Lock()
+	previous := j.texts[call.ID]
+	j.texts[call.ID] = text
+	j.lock.Unlock()
+
+	if text == previous {
+		return
+	}
+
+	if delta, ok := strings.CutPrefix(text, previous); ok {
+		j.write(Record{
+			Type:   RecordText,
+			CallID: call.ID,
+			Text:   delta,
+		})
+	} else {
+		j.write(Record{
+			Type:    RecordText,
+			CallID:  call.ID,
+			Text:    text,
+			Replace: true,
+		})
+	}
+}
+
+func (j *jsonDisplay) ConfirmResolved(resp gptscript.AuthResponse) {
+	j.write(Record{
+		Type:     RecordConfirmResolved,
+		CallID:   resp.ID,
+		Accepted: &resp.Accept,
+		Message:  resp.Message,
+	})
+}
+
+func (j *jsonDisplay) PromptResolved(resp gptscript.PromptResponse) {
+	j.write(Record{
+		Type: RecordPromptResolved,
+		ID:   resp.ID,
+	})
+}
+
+func (j *jsonDisplay) Done(run *gptscript.Run) {
+	if err := run.Err(); err != nil {
+		j.write(Record{
+			Type:  RecordError,
+			State: string(run.State()),
+			Error: err.Error(),
+		})
+		return
+	}
+
+	var text string
+	if call, ok := run.ParentCallFrame(); ok {
+		text = callText(call)
+	}
+	j.write(Record{
+		Type:  RecordResult,
+		Text:  text,
+		State: string(run.State()),
+	})
+}
+
+// callText returns the text output of a call without the tool calls it is making.
+func callText(call gptscript.CallFrame) string {
+	buf := &strings.Builder{}
+	for _, output := range call.Output {
+		content, _, _ := strings.Cut(output.Content, ToolCallHeader)
+		buf.WriteString(content)
+	}
+	return buf.String()
+}
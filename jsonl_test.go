@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+func TestJSONDisplayEvents(t *testing.T) {
+	var (
+		out = &bytes.Buffer{}
+		j   = newJSONDisplay(strings.NewReader("yes\n"), out)
+	)
+
+	call := gptscript.CallFrame{
+		CallContext: gptscript.CallContext{ID: "1", ToolName: "chat"},
+		Type:        gptscript.EventTypeCallStart,
+		Input:       "hi",
+	}
+	j.Event(gptscript.Frame{Call: &call})
+
+	call.Type = gptscript.EventTypeCallProgress
+	call.Output = []gptscript.Output{{Content: "Hello"}}
+	j.Event(gptscript.Frame{Call: &call})
+
+	call.Output = []gptscript.Output{{Content: "Hello world" + ToolCallHeader + "ls -> {}"}}
+	j.Event(gptscript.Frame{Call: &call})
+
+	call.Type = gptscript.EventTypeCallFinish
+	j.Event(gptscript.Frame{Call: &call})
+
+	if answer, ok, err := j.AskYesNo("\x1b[33mRun ls?\x1b[0m"); answer != Yes || !ok || err != nil {
+		t.Fatalf("AskYesNo() = %v, %v, %v", answer, ok, err)
+	}
+
+	var got []Record
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var record Record
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, record)
+	}
+
+	want := []Record{
+		{Type: RecordCallStart, CallID: "1", ToolName: "chat", Input: "hi"},
+		{Type: RecordText, CallID: "1", Text: "Hello"},
+		{Type: RecordText, CallID: "1", Text: " world"},
+		{Type: RecordCallEnd, CallID: "1", ToolName: "chat", Output: "Hello world"},
+		{Type: RecordAsk, Kind: "confirm", Message: "Run ls?"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d: %s", len(got), len(want), out.String())
+	}
+	for i := range want {
+		got[i].Time = want[i].Time
+		if got[i] != want[i] {
+			t.Errorf("record %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+// mcpInvocation returns the server and tool of an MCP sys tool name. Both "mcp.invoke.<tool> <server>" and
+// "mcp.invoke <server> <tool>" are understood.
+func mcpInvocation(sysToolName string) (server, tool string, ok bool) {
+	fields := strings.Fields(sysToolName)
+	if len(fields) == 0 {
+		return "", "", false
+	}
+
+	if fields[0] == "mcp.invoke" {
+		if len(fields) < 3 {
+			return "", "", false
+		}
+		return fields[1], strings.Join(fields[2:], " "), true
+	}
+
+	tool, ok = strings.CutPrefix(fields[0], "mcp.invoke.")
+	if !ok {
+		return "", "", false
+	}
+	if len(fields) > 1 {
+		server = fields[1]
+	}
+	return server, tool, true
+}
+
+func toMCPPrompt(server, tool string, event gptscript.Frame, offerAlways bool) (ConfirmPrompt, bool) {
+	msg := &strings.Builder{}
+	msg.WriteString(fmt.Sprintf("Call MCP tool %s", tool))
+	if server != "" {
+		msg.WriteString(fmt.Sprintf(" on server %s", server))
+	}
+	if event.Call.DisplayText != "" && !strings.HasPrefix(event.Call.DisplayText, "sys.") {
+		msg.WriteString(": ")
+		msg.WriteString(event.Call.DisplayText)
+	}
+	msg.WriteString("\n")
+
+	if args := inputArgs(event); len(args) > 0 {
+		content, err := json.MarshalIndent(args, "", "  ")
+		if err == nil {
+			msg.WriteString(markdownBox("json", string(content)))
+			msg.WriteString("\n")
+		}
+	} else if event.Call.Input != "" {
+		msg.WriteString(markdownBox("", event.Call.Input))
+		msg.WriteString("\n")
+	}
+
+	always := Trusted{
+		ToolName:  "mcp",
+		MCPServer: server,
+		MCPTool:   tool,
+	}
+	if server == "" || !offerAlways {
+		return confirmPrompt(msg.String(), fmt.Sprintf("(or allow all %s calls)", tool), always, offerAlways), true
+	}
+
+	msg.WriteString(fmt.Sprintf("(a: allow all %s calls, s: allow all tools of server %s)\nConfirm (y/n/a/s)", tool, server))
+	return ConfirmPrompt{
+		Message:     msg.String(),
+		AlwaysTrust: always,
+		ServerTrust: Trusted{
+			ToolName:  "mcp",
+			MCPServer: server,
+		},
+	}, true
+}
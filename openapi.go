@@ -0,0 +1,178 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	OpenAPIArgsTable = "table"
+	OpenAPIArgsJSON  = "json"
+)
+
+// OpenAPIConfirm configures the confirmation prompts of OpenAPI tools.
+type OpenAPIConfirm struct {
+	// Disable uses the generic confirmation prompt for OpenAPI tools
+	Disable bool
+	// ArgsFormat is how the arguments of run are displayed, either OpenAPIArgsTable (the default) or
+	// OpenAPIArgsJSON
+	ArgsFormat string
+	// AlwaysArgs are the arguments that "always" rules for run are constrained to. Allowing a call only
+	// allows later calls of the same operation with the same values for these arguments.
+	AlwaysArgs []string
+}
+
+func (o OpenAPIConfirm) toPrompt(toolName string, event gptscript.Frame, offerAlways bool) (ConfirmPrompt, bool) {
+	instructions := strings.Fields(event.Call.Tool.Instructions)
+	if len(instructions) < 3 {
+		return ConfirmPrompt{}, false
+	}
+
+	var (
+		command  = instructions[1]
+		oapiFile = instructions[2]
+	)
+
+	data := inputArgs(event)
+
+	switch command {
+	case "list":
+		return ConfirmPrompt{
+			Message: fmt.Sprintf("List operations in OpenAPI file %s\nConfirm (y/n)", oapiFile),
+		}, true
+	case "get-schema":
+		operation, _ := data["operation"].(string)
+		if operation == "" {
+			return ConfirmPrompt{}, false
+		}
+
+		return ConfirmPrompt{
+			Message: fmt.Sprintf("Get schema for operation %s in OpenAPI file %s\nConfirm (y/n)", operation, oapiFile),
+		}, true
+	case "run":
+		operation, _ := data["operation"].(string)
+		if operation == "" {
+			return ConfirmPrompt{}, false
+		}
+
+		var (
+			args      = openAPIArgs(data)
+			argValues = map[string]string{}
+			always    = Trusted{
+				ToolName: toolName,
+				ArgEquals: map[string]string{
+					"operation": operation,
+				},
+			}
+		)
+		for _, name := range o.AlwaysArgs {
+			if val, ok := args["args."+name]; ok {
+				argValues[name] = argString(val)
+			}
+		}
+		for name, val := range argValues {
+			always.ArgEquals["args."+name] = val
+		}
+
+		msg := &strings.Builder{}
+		msg.WriteString("Run operation ")
+		msg.WriteString(operation)
+		msg.WriteString(" in OpenAPI file ")
+		msg.WriteString(oapiFile)
+		if rendered := o.renderArgs(data); rendered != "" {
+			msg.WriteString(" with arguments\n")
+			msg.WriteString(rendered)
+		} else {
+			msg.WriteString("\n")
+		}
+
+		offer := &strings.Builder{}
+		offer.WriteString("(or allow all ")
+		offer.WriteString(operation)
+		offer.WriteString(" calls")
+		if len(argValues) > 0 {
+			names := maps.Keys(argValues)
+			sort.Strings(names)
+			offer.WriteString(" with")
+			for _, name := range names {
+				offer.WriteString(fmt.Sprintf(" %s=%s", name, argValues[name]))
+			}
+		}
+		offer.WriteString(")")
+
+		return confirmPrompt(msg.String(), offer.String(), always, offerAlways), true
+	}
+
+	return ConfirmPrompt{}, false
+}
+
+func (o OpenAPIConfirm) renderArgs(data map[string]any) string {
+	args := runArgs(data)
+	if len(args) == 0 {
+		return ""
+	}
+
+	if o.ArgsFormat == OpenAPIArgsJSON {
+		content, err := json.MarshalIndent(args, "", "  ")
+		if err != nil {
+			return ""
+		}
+		return markdownBox("json", string(content))
+	}
+
+	table := &strings.Builder{}
+	table.WriteString("| Argument | Value |\n|---|---|\n")
+	names := maps.Keys(args)
+	sort.Strings(names)
+	for _, name := range names {
+		table.WriteString(fmt.Sprintf("| %s | %s |\n", escapeTableCell(name), escapeTableCell(argString(args[name]))))
+	}
+
+	s, err := renderMarkdown(table.String())
+	if err != nil {
+		return table.String()
+	}
+	return s
+}
+
+// runArgs returns the arguments of an OpenAPI run call, which are either an object or a JSON string.
+func runArgs(data map[string]any) map[string]any {
+	switch args := data["args"].(type) {
+	case map[string]any:
+		return args
+	case string:
+		result := map[string]any{}
+		_ = json.Unmarshal([]byte(args), &result)
+		return result
+	}
+	return nil
+}
+
+// openAPIArgs adds the arguments of an OpenAPI run call as "args.<name>" so rules can match on them.
+func openAPIArgs(data map[string]any) map[string]any {
+	result := maps.Clone(data)
+	for name, val := range runArgs(data) {
+		result["args."+name] = val
+	}
+	return result
+}
+
+func argString(val any) string {
+	if s, ok := val.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprint(val)
+	}
+	return string(data)
+}
+
+func escapeTableCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pterm/pterm"
+)
+
+const pagerHelp = "enter/f next page, b previous page, g top, G bottom, /text search, ?text search backwards, n/N repeat search, q quit"
+
+// pager shows long content a page at a time, like less. Navigation is read a line at a time from the
+// prompt so it works alongside the readline prompt that owns stdin.
+type pager struct {
+	lines   []string
+	plain   []string
+	top     int
+	height  int
+	search  string
+	match   int
+	forward bool
+	message string
+}
+
+func newPager(content string, height int) *pager {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	plain := make([]string, len(lines))
+	for i, line := range lines {
+		plain[i] = strings.ToLower(pterm.RemoveColorFromString(line))
+	}
+	// Leave room for the status line and the prompt
+	height -= 2
+	if height < 1 {
+		height = 1
+	}
+	return &pager{
+		lines:   lines,
+		plain:   plain,
+		height:  height,
+		match:   -1,
+		forward: true,
+	}
+}
+
+func (p *pager) bottom() int {
+	return max(len(p.lines)-p.height, 0)
+}
+
+func (p *pager) scroll(n int) {
+	p.match = -1
+	p.top = min(max(p.top+n, 0), p.bottom())
+}
+
+// find moves to the next line matching the current search, wrapping around.
+func (p *pager) find(forward bool) {
+	if p.search == "" {
+		p.message = "no previous search"
+		return
+	}
+
+	from := p.match
+	if from < 0 {
+		// Start with the first line on screen
+		from = p.top - 1
+		if !forward {
+			from = p.top
+		}
+	}
+
+	for i := 1; i <= len(p.lines); i++ {
+		line := from - i
+		if forward {
+			line = from + i
+		}
+		line = (line%len(p.lines) + len(p.lines)) % len(p.lines)
+		if strings.Contains(p.plain[line], p.search) {
+			p.match = line
+			p.top = min(line, p.bottom())
+			return
+		}
+	}
+	p.message = fmt.Sprintf("pattern not found: %s", p.search)
+}
+
+// handle applies a command and returns false when the pager should close.
+func (p *pager) handle(cmd string) bool {
+	p.message = ""
+	switch {
+	case cmd == "" || cmd == "f" || cmd == " ":
+		p.scroll(p.height)
+	case cmd == "b":
+		p.scroll(-p.height)
+	case cmd == "j":
+		p.scroll(1)
+	case cmd == "k":
+		p.scroll(-1)
+	case cmd == "g":
+		p.scroll(-len(p.lines))
+	case cmd == "G":
+		p.scroll(len(p.lines))
+	case cmd == "q":
+		return false
+	case cmd == "n":
+		p.find(p.forward)
+	case cmd == "N":
+		p.find(!p.forward)
+	case strings.HasPrefix(cmd, "/") || strings.HasPrefix(cmd, "?"):
+		if search := strings.ToLower(cmd[1:]); search != "" {
+			p.search = search
+			p.match = -1
+		}
+		p.forward = cmd[0] == '/'
+		p.find(p.forward)
+	default:
+		p.message = pagerHelp
+	}
+	return true
+}
+
+func (p *pager) view() string {
+	buf := &strings.Builder{}
+	end := min(p.top+p.height, len(p.lines))
+	for _, line := range p.lines[p.top:end] {
+		if p.search != "" && strings.Contains(strings.ToLower(line), p.search) {
+			line = highlight(line, p.search)
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+	for i := end - p.top; i < p.height; i++ {
+		buf.WriteString("~\n")
+	}
+
+	status := fmt.Sprintf("lines %d-%d of %d", p.top+1, end, len(p.lines))
+	if end == len(p.lines) {
+		status += " (END)"
+	}
+	if p.message != "" {
+		status += " - " + p.message
+	}
+	buf.WriteString(color.New(color.ReverseVideo).Sprint(status))
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+// highlight marks the case-insensitive matches of search in a line without color codes in the match.
+func highlight(line, search string) string {
+	// Match on the line itself, lowercasing can change the byte length of a line
+	re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(search))
+	buf := &strings.Builder{}
+	last := 0
+	for _, match := range re.FindAllStringIndex(line, -1) {
+		buf.WriteString(line[last:match[0]])
+		buf.WriteString(color.New(color.ReverseVideo).Sprint(line[match[0]:match[1]]))
+		last = match[1]
+	}
+	buf.WriteString(line[last:])
+	return buf.String()
+}
+
+// page runs the pager until the user quits or the prompt is closed.
+func (p *pager) page(out io.Writer, prompt func(string) (string, bool)) {
+	for {
+		fmt.Fprint(out, "\033[H\033[2J")
+		fmt.Fprint(out, p.view())
+		cmd, ok := prompt(":")
+		if !ok || !p.handle(strings.TrimSpace(cmd)) {
+			return
+		}
+	}
+}
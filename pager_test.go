@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestPager(t *testing.T) {
+	var lines []string
+	for i := range 20 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	lines[3] = "Needle one"
+	lines[15] = "needle two"
+
+	p := newPager(strings.Join(lines, "\n"), 7)
+	if p.height != 5 {
+		t.Fatalf("expected height 5, got %d", p.height)
+	}
+
+	steps := []struct {
+		cmd string
+		top int
+	}{
+		{"", 5},
+		{"b", 0},
+		{"G", 15},
+		{"b", 10},
+		{"g", 0},
+		{"/needle", 3},
+		{"n", 15},
+		{"n", 3},
+		{"N", 15},
+		{"?NEEDLE", 3},
+		{"j", 4},
+		{"k", 3},
+	}
+	for _, step := range steps {
+		if !p.handle(step.cmd) {
+			t.Fatalf("%q closed the pager", step.cmd)
+		}
+		if p.top != step.top {
+			t.Fatalf("after %q expected top %d, got %d", step.cmd, step.top, p.top)
+		}
+	}
+
+	p.handle("/missing")
+	if !strings.Contains(p.view(), "pattern not found: missing") {
+		t.Fatalf("expected not found message, got %s", p.view())
+	}
+	if p.handle("q") {
+		t.Fatal("expected q to close the pager")
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	noColor := color.NoColor
+	t.Cleanup(func() { color.NoColor = noColor })
+	color.NoColor = false
+
+	marked := color.New(color.ReverseVideo).Sprint
+	for _, test := range []struct {
+		line, search, expected string
+	}{
+		{"an Error and an error", "error", "an " + marked("Error") + " and an " + marked("error")},
+		// Lowercasing invalid UTF-8 changes the byte length of the line
+		{"\xff\xff\xff error", "error", "\xff\xff\xff " + marked("error")},
+		{"İİİ error", "error", "İİİ " + marked("error")},
+		{"nothing here", "error", "nothing here"},
+	} {
+		if got := highlight(test.line, test.search); got != test.expected {
+			t.Fatalf("highlight(%q, %q) expected %q, got %q", test.line, test.search, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// pathArg returns the file or directory argument of the sys file tools.
+func pathArg(args map[string]any) string {
+	for _, name := range []string{"filename", "dir", "location"} {
+		if val, _ := args[name].(string); val != "" {
+			return val
+		}
+	}
+	return ""
+}
+
+// resolvePath returns the absolute, cleaned form of p with symlinks resolved. Parts of the path that do not
+// exist yet are appended to the resolved form of the deepest existing parent.
+func resolvePath(p string) string {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return filepath.Clean(p)
+	}
+
+	var (
+		dir  = abs
+		rest []string
+	)
+	for {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...)
+		}
+		if !os.IsNotExist(err) {
+			return abs
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return abs
+		}
+		rest = append([]string{filepath.Base(dir)}, rest...)
+		dir = parent
+	}
+}
+
+// withinDir returns true if the already resolved path p is dir or inside of dir.
+func withinDir(p, dir string) bool {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// workspaceWarning returns a warning line if the resolved path is outside of the workspace.
+func workspaceWarning(resolved, workspace string) string {
+	if workspace == "" || withinDir(resolved, resolvePath(workspace)) {
+		return ""
+	}
+	return WarningStyle.Sprintf("WARNING: %s is outside of the workspace %s", resolved, workspace) + "\n"
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
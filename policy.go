@@ -0,0 +1,199 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+type PolicyAction string
+
+const (
+	PolicyAllow = PolicyAction("allow")
+	PolicyDeny  = PolicyAction("deny")
+	PolicyAsk   = PolicyAction("ask")
+)
+
+// Policy is a declarative set of rules that decide confirmations before the user is asked. Rules are
+// evaluated in order and the first matching rule wins.
+type Policy struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+// PolicyRule matches a sys tool call. Every field that is set must match for the rule to apply, a rule
+// with no fields set matches every call.
+type PolicyRule struct {
+	Action PolicyAction `json:"action"`
+	// Tool is a glob matched against the sys tool name without the "sys." prefix, for example "exec" or "http.*"
+	Tool string `json:"tool,omitempty"`
+	// Command is a prefix matched against the command of sys.exec
+	Command string `json:"command,omitempty"`
+	// CommandGlob is a glob matched against the full command of sys.exec
+	CommandGlob string `json:"commandGlob,omitempty"`
+	// Path is a glob matched against the cleaned path argument of file tools such as sys.write or sys.read,
+	// "**" matches across directories
+	Path string `json:"path,omitempty"`
+	// Operation is a glob matched against the OpenAPI operation
+	Operation string `json:"operation,omitempty"`
+	// MCPServer and MCPTool are globs matched against the MCP server and tool of an MCP invocation
+	MCPServer string `json:"mcpServer,omitempty"`
+	MCPTool   string `json:"mcpTool,omitempty"`
+	// Reason is sent back to the model when the rule denies a call
+	Reason string `json:"reason,omitempty"`
+}
+
+func LoadPolicy(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", file, err)
+	}
+
+	for i, rule := range policy.Rules {
+		switch rule.Action {
+		case PolicyAllow, PolicyDeny, PolicyAsk:
+		default:
+			return nil, fmt.Errorf("invalid action %q for rule %d in policy file %s", rule.Action, i, file)
+		}
+	}
+
+	return &policy, nil
+}
+
+// Evaluate returns the action and reason of the first rule matching the event, or an empty action if no
+// rule matches.
+func (p *Policy) Evaluate(event gptscript.Frame) (PolicyAction, string) {
+	if p == nil || event.Call == nil {
+		return "", ""
+	}
+
+	sysToolName, isSysTool := isSysTool(event, "")
+	if !isSysTool {
+		return "", ""
+	}
+
+	args := inputArgs(event)
+	for _, rule := range p.Rules {
+		if rule.matches(sysToolName, args) {
+			return rule.Action, rule.Reason
+		}
+	}
+
+	return "", ""
+}
+
+func (r PolicyRule) matches(sysToolName string, args map[string]any) bool {
+	fields := strings.Fields(sysToolName)
+	if len(fields) == 0 {
+		return false
+	}
+
+	if r.Tool != "" && !globMatch(r.Tool, fields[0], false) {
+		return false
+	}
+
+	if r.Command != "" || r.CommandGlob != "" {
+		command, _ := args["command"].(string)
+		if fields[0] != "exec" || !r.matchesCommand(command) {
+			return false
+		}
+	}
+
+	if r.Path != "" {
+		filename := pathArg(args)
+		if filename == "" || !globMatch(r.Path, filepath.ToSlash(filepath.Clean(filename)), true) {
+			return false
+		}
+	}
+
+	if r.Operation != "" {
+		operation, _ := args["operation"].(string)
+		if fields[0] != "openapi" || operation == "" || !globMatch(r.Operation, operation, false) {
+			return false
+		}
+	}
+
+	if r.MCPServer != "" || r.MCPTool != "" {
+		server, tool, ok := mcpInvocation(sysToolName)
+		if !ok {
+			return false
+		}
+		if r.MCPServer != "" && !globMatch(r.MCPServer, server, false) {
+			return false
+		}
+		if r.MCPTool != "" && !globMatch(r.MCPTool, tool, false) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesCommand matches the rule against each command of a compound command line. An allow rule must
+// match every command, deny and ask rules match if any command matches.
+func (r PolicyRule) matchesCommand(command string) bool {
+	segments := parseShell(command)
+	if len(segments) == 0 {
+		return false
+	}
+
+	for _, segment := range segments {
+		text := segment.String()
+		ok := (r.Command == "" || text == r.Command || strings.HasPrefix(text, r.Command+" ")) &&
+			(r.CommandGlob == "" || globMatch(r.CommandGlob, text, false))
+		if ok && r.Action != PolicyAllow {
+			return true
+		} else if !ok && r.Action == PolicyAllow {
+			return false
+		}
+	}
+
+	return r.Action == PolicyAllow
+}
+
+// globMatch matches s against pattern where "*" matches any characters and "?" matches a single character.
+// In path mode "*" and "?" do not match "/" and "**" matches across directories.
+func globMatch(pattern, s string, path bool) bool {
+	var (
+		expr = &strings.Builder{}
+		many = ".*"
+		one  = "."
+	)
+	if path {
+		many = "[^/]*"
+		one = "[^/]"
+	}
+
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*':
+			if path && i+1 < len(pattern) && pattern[i+1] == '*' {
+				expr.WriteString(".*")
+				i++
+			} else {
+				expr.WriteString(many)
+			}
+		case '?':
+			expr.WriteString(one)
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return false
+	}
+	return re.MatchString(s)
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+func TestPolicyEvaluate(t *testing.T) {
+	policy := &Policy{
+		Rules: []PolicyRule{
+			{Action: PolicyDeny, CommandGlob: "rm -rf *", Reason: "no recursive deletes"},
+			{Action: PolicyAllow, Command: "git status"},
+			{Action: PolicyAsk, Command: "git"},
+			{Action: PolicyAllow, Tool: "write", Path: "src/**"},
+			{Action: PolicyDeny, Tool: "write"},
+			{Action: PolicyAllow, Operation: "list*"},
+			{Action: PolicyAllow, MCPServer: "files", MCPTool: "read_*"},
+			{Action: PolicyAllow, Tool: "http.*"},
+		},
+	}
+
+	testCases := []struct {
+		name   string
+		event  gptscript.Frame
+		action PolicyAction
+	}{
+		{name: "DenyGlob", event: sysEvent("#!sys.exec", `{"command": "rm -rf /tmp/x"}`), action: PolicyDeny},
+		{name: "AllowPrefix", event: sysEvent("#!sys.exec", `{"command": "git status --short"}`), action: PolicyAllow},
+		{name: "AllowCompound", event: sysEvent("#!sys.exec", `{"command": "git status; git status"}`), action: PolicyAllow},
+		{name: "AllowNotCovered", event: sysEvent("#!sys.exec", `{"command": "git status; curl x"}`), action: PolicyAsk},
+		{name: "AskPrefix", event: sysEvent("#!sys.exec", `{"command": "git push"}`), action: PolicyAsk},
+		{name: "NoMatch", event: sysEvent("#!sys.exec", `{"command": "ls"}`), action: ""},
+		{name: "AllowPath", event: sysEvent("#!sys.write", `{"filename": "src/a/b.go"}`), action: PolicyAllow},
+		{name: "DenyEscapedPath", event: sysEvent("#!sys.write", `{"filename": "src/../../etc/passwd"}`), action: PolicyDeny},
+		{name: "Operation", event: sysEvent("#!sys.openapi run spec.yaml x", `{"operation": "listPets"}`), action: PolicyAllow},
+		{name: "MCP", event: sysEvent("#!sys.mcp.invoke.read_file files", `{}`), action: PolicyAllow},
+		{name: "MCPOtherServer", event: sysEvent("#!sys.mcp.invoke.read_file other", `{}`), action: ""},
+		{name: "ToolGlob", event: sysEvent("#!sys.http.get", `{"url": "https://example.com"}`), action: PolicyAllow},
+		{name: "NotSysTool", event: sysEvent("#!/bin/sh", `{}`), action: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, _ := policy.Evaluate(tc.event); got != tc.action {
+				t.Errorf("Evaluate() = %q, want %q", got, tc.action)
+			}
+		})
+	}
+}
+
+func TestPolicyAskOffersNoRule(t *testing.T) {
+	c := &Confirm{
+		policy: &Policy{
+			Rules: []PolicyRule{
+				{Action: PolicyAsk, Command: "git"},
+				{Action: PolicyAsk, MCPServer: "files"},
+			},
+		},
+	}
+
+	for _, event := range []gptscript.Frame{
+		sysEvent("#!sys.exec", `{"command": "git push"}`),
+		sysEvent("#!sys.mcp.invoke.read_file files", `{}`),
+	} {
+		prompt, trusted, err := c.IsTrusted(event)
+		if err != nil || trusted {
+			t.Fatalf("IsTrusted() = %v, %v, expected to ask", trusted, err)
+		}
+		if prompt.AlwaysTrust.ToolName != "" || prompt.ServerTrust.ToolName != "" {
+			t.Errorf("expected no rules, got %v and %v", prompt.AlwaysTrust, prompt.ServerTrust)
+		}
+		if !strings.HasSuffix(prompt.Message, "\nConfirm (y/n)") || strings.Contains(prompt.Message, "allow all") {
+			t.Errorf("expected a (y/n) prompt, got %q", prompt.Message)
+		}
+	}
+}
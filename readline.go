@@ -10,7 +10,6 @@ import (
 
 	"github.com/adrg/xdg"
 	"github.com/chzyer/readline"
-	"github.com/fatih/color"
 )
 
 type prompter struct {
@@ -32,7 +31,7 @@ func newReadlinePrompter(tool string) (*prompter, error) {
 	}
 
 	l, err := readline.NewEx(&readline.Config{
-		Prompt:            color.GreenString("> "),
+		Prompt:            PromptStyle.Sprint("> "),
 		HistoryFile:       historyFile,
 		InterruptPrompt:   "^C",
 		EOFPrompt:         "exit",
@@ -81,7 +80,7 @@ func (r *prompter) Readline(allowEmpty bool) func() (string, bool) {
 }
 
 func (r *prompter) SetPrompt(text string) {
-	r.prompt = color.GreenString(text+">") + " "
+	r.prompt = PromptStyle.Sprint(text+">") + " "
 	r.readliner.SetPrompt(r.prompt)
 }
 
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+var secretName = regexp.MustCompile(`(?i)(auth|token|secret|password|passwd|api[-_]?key|cookie|credential|session|signature)`)
+
+// isSecretName returns true if a header, parameter or field name is likely to hold a secret.
+func isSecretName(name string) bool {
+	return secretName.MatchString(name)
+}
+
+// redactURL removes passwords and secret query parameters from a URL.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+
+	query := u.Query()
+	changed := false
+	for key := range query {
+		if isSecretName(key) {
+			query.Set(key, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		u.RawQuery = query.Encode()
+	}
+
+	return u.String()
+}
+
+// redactValue walks a decoded JSON value and redacts anything matching one of the patterns. Strings holding
+// JSON objects, such as tool inputs and outputs, are decoded and the values of sensitive keys in them are
+// redacted too. Keys of v itself are structural and never redacted by name.
+func redactValue(v any, sensitive map[string]struct{}, patterns []*regexp.Regexp) any {
+	return redactJSON(v, nil, sensitive, patterns)
+}
+
+// redactJSON redacts v, redacting values of keys in names by name.
+func redactJSON(v any, names, sensitive map[string]struct{}, patterns []*regexp.Regexp) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, val := range v {
+			if _, ok := names[key]; ok {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(val, names, sensitive, patterns)
+			}
+		}
+		return v
+	case []any:
+		for i, val := range v {
+			v[i] = redactJSON(val, names, sensitive, patterns)
+		}
+		return v
+	case string:
+		if len(sensitive) > 0 && strings.HasPrefix(strings.TrimSpace(v), "{") {
+			var obj map[string]any
+			if json.Unmarshal([]byte(v), &obj) == nil {
+				if data, err := json.Marshal(redactJSON(obj, sensitive, sensitive, patterns)); err == nil {
+					return string(data)
+				}
+			}
+		}
+		for _, pattern := range patterns {
+			v = pattern.ReplaceAllString(v, redacted)
+		}
+		return v
+	}
+	return v
+}
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"atomicgo.dev/cursor"
+	"github.com/gptscript-ai/go-gptscript"
+	"golang.org/x/exp/maps"
+)
+
+type ReplayOptions struct {
+	// Speed multiplies the original speed of the recording, 0 means the original speed
+	Speed float64
+	// MaxDelay caps the time waited between two events, 0 means no cap
+	MaxDelay time.Duration
+	// Step waits for ENTER before each event instead of following the recorded timing
+	Step     bool
+	Headless bool
+}
+
+func completeReplay(opts ...ReplayOptions) (result ReplayOptions) {
+	for _, opt := range opts {
+		result.Speed = first(opt.Speed, result.Speed)
+		result.MaxDelay = first(opt.MaxDelay, result.MaxDelay)
+		result.Step = first(opt.Step, result.Step)
+		result.Headless = first(opt.Headless, result.Headless)
+	}
+	if result.Speed <= 0 {
+		result.Speed = 1
+	}
+	if !isTerminal(os.Stdout) {
+		result.Headless = true
+	}
+	return
+}
+
+type eventRecord struct {
+	Time  time.Time       `json:"time"`
+	Event gptscript.Frame `json:"event"`
+}
+
+// Replay renders an event log written by Run with the EventLog option as if the run was happening again.
+// No model or network is needed and nothing is asked. Confirmations are shown with the calls they belong to,
+// prompts for user input are only shown when stepping through the events.
+func Replay(ctx context.Context, eventLog string, opts ...ReplayOptions) error {
+	opt := completeReplay(opts...)
+	if opt.Step && opt.Headless {
+		return fmt.Errorf("step by step replay requires a terminal")
+	}
+
+	f, err := os.Open(eventLog)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	ui, err := newUserInterface("replay "+eventLog, RunOptions{Headless: opt.Headless})
+	if err != nil {
+		return err
+	}
+	defer ui.Close()
+	if !opt.Headless {
+		defer cursor.Show()
+	}
+
+	var (
+		decoder = json.NewDecoder(f)
+		input   string
+		calls   = gptscript.CallFrames{}
+		last    time.Time
+		text    = func() string { return "" }
+	)
+
+	for {
+		var record eventRecord
+		if err := decoder.Decode(&record); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to read event log %s: %w", eventLog, err)
+		}
+
+		if opt.Step {
+			line, ok := ui.Ask(fmt.Sprintf("%s\nENTER for the next event, q to quit", describeEvent(record.Event)), false, true)
+			if !ok || strings.TrimSpace(line) == "q" {
+				return nil
+			}
+		} else if !last.IsZero() {
+			delay := time.Duration(float64(record.Time.Sub(last)) / opt.Speed)
+			if opt.MaxDelay > 0 {
+				delay = min(delay, opt.MaxDelay)
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(delay):
+			}
+		}
+		last = record.Time
+
+		event := record.Event
+		switch {
+		case event.Run != nil && event.Run.Type == gptscript.EventTypeRunStart:
+			input = event.Run.Input
+			calls = gptscript.CallFrames{}
+		case event.Run != nil && event.Run.Type == gptscript.EventTypeRunFinish:
+			ui.Finished(text())
+			text = func() string { return "" }
+		case event.Call != nil:
+			calls[event.Call.ID] = *event.Call
+			// The text is rendered later by the display, so it gets its own copy of the calls
+			text = renderCalls(input, maps.Clone(calls))
+			ui.Progress(text)
+			if streamer, ok := ui.(callStreamer); ok {
+				streamer.StreamCalls(input, calls, "")
+			}
+		}
+	}
+
+	if content := text(); content != "" {
+		ui.Finished(content)
+	}
+	return nil
+}
+
+func describeEvent(event gptscript.Frame) string {
+	switch {
+	case event.Run != nil:
+		return fmt.Sprintf("[%s]", event.Run.Type)
+	case event.Call != nil:
+		return fmt.Sprintf("[%s] %s", event.Call.Type, first(event.Call.ToolName, event.Call.Tool.Name))
+	case event.Prompt != nil:
+		return fmt.Sprintf("[%s] %s", event.Prompt.Type, event.Prompt.Message)
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+func TestReplay(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "events.jsonl")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		start   = time.Now()
+		encoder = json.NewEncoder(f)
+		records = []eventRecord{
+			{Event: gptscript.Frame{Run: &gptscript.RunFrame{Type: gptscript.EventTypeRunStart, Input: "hello"}}},
+		}
+		content string
+	)
+	for i := range 5 {
+		content += fmt.Sprintf("Hello from the replay %d\n", i)
+		records = append(records, eventRecord{Event: gptscript.Frame{Call: &gptscript.CallFrame{
+			CallContext: gptscript.CallContext{ID: "1"},
+			Type:        gptscript.EventTypeCallProgress,
+			Start:       start,
+			Output:      []gptscript.Output{{Content: content}},
+		}}})
+	}
+	records = append(records, eventRecord{Event: gptscript.Frame{Run: &gptscript.RunFrame{Type: gptscript.EventTypeRunFinish}}})
+	for i, record := range records {
+		record.Time = start.Add(time.Duration(i) * time.Millisecond)
+		if err := encoder.Encode(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+
+	err = Replay(context.Background(), file, ReplayOptions{Headless: true})
+	_ = w.Close()
+	os.Stdout = stdout
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "> hello\n\n" + content
+	if out := <-output; out != expected {
+		t.Fatalf("expected replay output:\n%s\ngot:\n%s", expected, out)
+	}
+}
@@ -0,0 +1,8 @@
+package tui
+
+import (
+	"os"
+)
+
+// Windows has no resize signal, the width is read again on the next render
+func notifyResize(chan<- os.Signal) {}
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// RetryOptions configures automatic retries of turns that fail with a transient error, like a rate limit
+// or a reset connection.
+type RetryOptions struct {
+	// MaxAttempts is how many times a failed turn is retried, zero disables automatic retries
+	MaxAttempts int
+	// Backoff is the delay before the first retry, it doubles with every attempt. Defaults to one second.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries. Defaults to thirty seconds.
+	MaxBackoff time.Duration
+}
+
+var transientErrors = []string{
+	"rate limit",
+	"too many requests",
+	"429",
+	"502",
+	"503",
+	"504",
+	"overloaded",
+	"temporarily unavailable",
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"unexpected eof",
+	"timeout",
+}
+
+func isTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range transientErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+type retrier struct {
+	opts    RetryOptions
+	attempt int
+}
+
+func newRetrier(opts *RetryOptions) *retrier {
+	r := &retrier{}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.Backoff <= 0 {
+		r.opts.Backoff = time.Second
+	}
+	if r.opts.MaxBackoff <= 0 {
+		r.opts.MaxBackoff = 30 * time.Second
+	}
+	return r
+}
+
+// reset is called when the user submits a new turn.
+func (r *retrier) reset() {
+	r.attempt = 0
+}
+
+// next returns how long to wait before retrying a turn that failed with err, or false if it should not
+// be retried.
+func (r *retrier) next(err error) (time.Duration, bool) {
+	if r.attempt >= r.opts.MaxAttempts || !isTransient(err) {
+		return 0, false
+	}
+	delay := r.opts.Backoff << r.attempt
+	if delay > r.opts.MaxBackoff || delay <= 0 {
+		delay = r.opts.MaxBackoff
+	}
+	r.attempt++
+	return delay, true
+}
@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRetrier(t *testing.T) {
+	r := newRetrier(&RetryOptions{
+		MaxAttempts: 3,
+		Backoff:     time.Second,
+		MaxBackoff:  3 * time.Second,
+	})
+
+	if _, ok := r.next(errors.New("invalid tool")); ok {
+		t.Fatal("expected permanent error to not be retried")
+	}
+
+	rateLimited := fmt.Errorf("failed: %w", errors.New("429 Too Many Requests"))
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		delay, ok := r.next(rateLimited)
+		if !ok || delay != want {
+			t.Fatalf("expected retry after %v, got %v, %v", want, delay, ok)
+		}
+	}
+	if _, ok := r.next(rateLimited); ok {
+		t.Fatal("expected no more retries")
+	}
+
+	r.reset()
+	if _, ok := r.next(errors.New("read: connection reset by peer")); !ok {
+		t.Fatal("expected retry after reset")
+	}
+
+	if _, ok := newRetrier(nil).next(rateLimited); ok {
+		t.Fatal("expected retries to be disabled by default")
+	}
+}
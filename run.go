@@ -2,7 +2,6 @@ package tui
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -27,16 +26,29 @@ type RunOptions struct {
 	AppName               string
 	Eval                  []gptscript.ToolDef
 	TrustedRepoPrefixes   []string
+	WorkspaceScopedTrust  bool
+	PolicyFile            string
+	AuditLog              string
+	OpenAPIConfirm        *OpenAPIConfirm
+	Confirmer             Confirmer
+	Headless              bool
+	JSONOutput            bool
 	DisableCache          bool
 	CredentialOverrides   []string
 	Input                 string
 	SubTool               string
 	ChatState             string
 	SaveChatStateFile     string
+	Session               string
+	Transcript            string
 	Workspace             string
 	UserStartConversation *bool
 	Location              string
 	EventLog              string
+	EventLogOptions       *EventLogOptions
+	Retry                 *RetryOptions
+	Prices                map[string]Price
+	Theme                 *Theme
 	LoadMessage           string
 	ForceSequential       bool
 	Client                *gptscript.GPTScript
@@ -59,24 +71,48 @@ func complete(opts ...RunOptions) (result RunOptions, closeClient func(), _ erro
 
 	for _, opt := range opts {
 		result.TrustedRepoPrefixes = append(result.TrustedRepoPrefixes, opt.TrustedRepoPrefixes...)
+		result.WorkspaceScopedTrust = first(opt.WorkspaceScopedTrust, result.WorkspaceScopedTrust)
+		result.PolicyFile = first(opt.PolicyFile, result.PolicyFile)
+		result.AuditLog = first(opt.AuditLog, result.AuditLog)
+		result.OpenAPIConfirm = first(opt.OpenAPIConfirm, result.OpenAPIConfirm)
+		if opt.Confirmer != nil {
+			result.Confirmer = opt.Confirmer
+		}
+		result.Headless = first(opt.Headless, result.Headless)
+		result.JSONOutput = first(opt.JSONOutput, result.JSONOutput)
 		result.DisableCache = first(opt.DisableCache, result.DisableCache)
 		result.CredentialOverrides = append(result.CredentialOverrides, opt.CredentialOverrides...)
 		result.SubTool = first(opt.SubTool, result.SubTool)
 		result.Workspace = first(opt.Workspace, result.Workspace)
 		result.SaveChatStateFile = first(opt.SaveChatStateFile, result.SaveChatStateFile)
+		result.Session = first(opt.Session, result.Session)
+		result.Transcript = first(opt.Transcript, result.Transcript)
 		result.ChatState = first(opt.ChatState, result.ChatState)
 		result.Eval = append(result.Eval, opt.Eval...)
 		result.AppName = first(opt.AppName, result.AppName)
 		result.UserStartConversation = first(opt.UserStartConversation, result.UserStartConversation)
 		result.Location = first(opt.Location, result.Location)
 		result.EventLog = first(opt.EventLog, result.EventLog)
+		result.EventLogOptions = first(opt.EventLogOptions, result.EventLogOptions)
+		result.Retry = first(opt.Retry, result.Retry)
+		result.Theme = first(opt.Theme, result.Theme)
+		for model, price := range opt.Prices {
+			if result.Prices == nil {
+				result.Prices = map[string]Price{}
+			}
+			result.Prices[model] = price
+		}
 		result.LoadMessage = first(opt.LoadMessage, result.LoadMessage)
 		result.ForceSequential = first(opt.ForceSequential, result.ForceSequential)
 		result.Client = first(opt.Client, result.Client)
 		result.ClientOpts = first(opt.ClientOpts, result.ClientOpts)
 	}
 	if result.AppName == "" {
-		result.AppName = "gptscript-tui"
+		result.AppName = defaultAppName
+	}
+
+	if !isTerminal(os.Stdout) || result.JSONOutput {
+		result.Headless = true
 	}
 
 	if result.Workspace == "" {
@@ -125,19 +161,25 @@ func Run(ctx context.Context, tool string, opts ...RunOptions) error {
 		opt, closeClient, err = complete(opts...)
 		input                 = opt.Input
 		localCtx, cancel      = signal.NotifyContext(ctx, os.Interrupt)
-		eventOut              io.Writer
+		eventLog              *eventLogger
 	)
 	defer cancel()
-	defer cursor.Show()
 
 	if err != nil {
 		return err
 	}
+	if !opt.Headless {
+		defer cursor.Show()
+	}
 	defer closeClient()
 	if opt.deleteWorkspaceOn {
 		defer os.RemoveAll(opt.Workspace)
 	}
 
+	if err := applyTheme(opt.Theme); err != nil {
+		return err
+	}
+
 	startCtx, started := context.WithCancel(ctx)
 	defer started()
 	go func() {
@@ -147,23 +189,70 @@ func Run(ctx context.Context, tool string, opts ...RunOptions) error {
 		case <-time.After(time.Second):
 		}
 
-		if opt.LoadMessage != "" {
+		if opt.LoadMessage != "" && !opt.JSONOutput {
 			fmt.Printf(opt.LoadMessage)
 		}
 	}()
 
 	client := opt.Client
-	confirm, err := NewConfirm(opt.AppName, client, opt.TrustedRepoPrefixes...)
-	if err != nil {
-		return err
+	confirmer := opt.Confirmer
+	if confirmer == nil {
+		confirmer, err = NewConfirmWithOptions(opt.AppName, client, ConfirmOptions{
+			TrustedRepoPrefixes:  opt.TrustedRepoPrefixes,
+			Workspace:            opt.Workspace,
+			WorkspaceScopedTrust: opt.WorkspaceScopedTrust,
+			PolicyFile:           opt.PolicyFile,
+			AuditLog:             opt.AuditLog,
+			OpenAPI:              opt.OpenAPIConfirm,
+		})
+		if err != nil {
+			return err
+		}
 	}
 
-	ui, err := newDisplay(tool)
+	var defaultModel string
+	if opt.ClientOpts != nil {
+		defaultModel = opt.ClientOpts.DefaultModel
+	}
+
+	conv := &conversation{
+		appName:      opt.AppName,
+		prices:       opt.Prices,
+		defaultModel: defaultModel,
+		session: Session{
+			Name: opt.Session,
+			Tool: tool,
+		},
+	}
+	if conv.session.Name != "" {
+		saved, ok, err := LoadSession(opt.AppName, tool, conv.session.Name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			conv.session = saved
+			opt.ChatState = first(opt.ChatState, saved.ChatState)
+		}
+	}
+	if len(conv.session.Turns) == 0 {
+		conv.initial = opt.ChatState
+	}
+
+	ui, err := newUserInterface(tool, opt)
 	if err != nil {
 		return err
 	}
 	defer ui.Close()
 
+	observer, _ := ui.(runObserver)
+	streamer, _ := ui.(callStreamer)
+	confirmer, commands := setupChat(confirmer, conv, ui)
+	errOut := io.Writer(os.Stdout)
+	if observer != nil {
+		// Stdout is reserved for the records of the observer
+		errOut = os.Stderr
+	}
+
 	if opt.UserStartConversation == nil {
 		tools := opt.Eval
 		if len(tools) == 0 {
@@ -188,21 +277,24 @@ func Run(ctx context.Context, tool string, opts ...RunOptions) error {
 
 	if firstInput == "" && opt.UserStartConversation != nil && *opt.UserStartConversation {
 		var ok bool
-		firstInput, ok = ui.Prompt("")
+		firstInput, ok = promptLine(ui, commands, "")
 		if !ok {
-			return nil
+			return ui.Err()
 		}
 	}
 
 	if firstInput == "" && opt.ChatState != "" {
 		var ok bool
-		firstInput, ok = ui.Prompt("Resuming conversation")
+		firstInput, ok = promptLine(ui, commands, "Resuming conversation")
 		if !ok {
-			return nil
+			return ui.Err()
 		}
 	}
 
-	var run *gptscript.Run
+	if observer != nil && firstInput != "" {
+		observer.Input(firstInput)
+	}
+
 	runOpt := gptscript.Options{
 		GlobalOptions:       gptscript.GlobalOptions{},
 		Confirm:             true,
@@ -217,56 +309,75 @@ func Run(ctx context.Context, tool string, opts ...RunOptions) error {
 		Location:            opt.Location,
 		ForceSequential:     opt.ForceSequential,
 	}
-	if len(opt.Eval) == 0 {
-		run, err = client.Run(localCtx, tool, runOpt)
-		if err != nil {
-			return err
-		}
-		defer run.Close()
-	} else {
-		run, err = client.Evaluate(localCtx, runOpt, opt.Eval...)
-		if err != nil {
-			return err
+	startRun := func(ctx context.Context, input, chatState string) (*gptscript.Run, error) {
+		runOpt := runOpt
+		runOpt.Input = input
+		runOpt.ChatState = chatState
+		if len(opt.Eval) == 0 {
+			return client.Run(ctx, tool, runOpt)
 		}
-		defer run.Close()
+		return client.Evaluate(ctx, runOpt, opt.Eval...)
 	}
 
+	conv.begin(opt.Workspace)
+	run, err := startRun(localCtx, firstInput, opt.ChatState)
+	if err != nil {
+		return err
+	}
+	defer run.Close()
+	submitted := firstInput
+	retries := newRetrier(opt.Retry)
+
 	if opt.EventLog != "" {
-		f, err := os.OpenFile(opt.EventLog, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+		eventLog, err = newEventLogger(opt.EventLog, opt.EventLogOptions)
 		if err != nil {
 			return err
 		}
-		defer f.Close()
-		eventOut = f
+		defer eventLog.Close()
+	}
+
+	status := &turnStatus{
+		defaultModel: defaultModel,
+		workspace:    opt.Workspace,
+	}
+	if bar, ok := ui.(statusBar); ok {
+		bar.SetStatus(status.String)
 	}
 
 	for {
 		text := func() string { return "" }
+		status.begin(run, conv.session.Name)
 
 		for event := range run.Events() {
 			started()
-			if eventOut != nil && (event.Call == nil || event.Call.Type != gptscript.EventTypeCallProgress) {
-				if err := json.NewEncoder(eventOut).Encode(map[string]any{
-					"time":  time.Now(),
-					"event": event,
-				}); err != nil {
+			if eventLog != nil {
+				if err := eventLog.Log(event); err != nil {
 					return err
 				}
 			}
 
 			if event.Call != nil {
-				text = render(input, run)
+				calls := run.Calls()
+				text = withUsage(renderCalls(input, calls), calls, opt.Prices, defaultModel)
 				ui.Progress(text)
+				if streamer != nil {
+					streamer.StreamCalls(input, calls, turnUsage(calls, opt.Prices, defaultModel).String())
+				}
 			}
 
-			if ok, err := confirm.HandlePrompt(localCtx, event, ui.Ask); !ok {
-				return nil
+			if observer != nil {
+				observer.Event(event)
+			}
+
+			if ok, err := handlePrompt(localCtx, client, confirmer, event, ui.Ask); !ok {
+				return ui.Err()
 			} else if err != nil && localCtx.Err() == nil {
 				return err
 			}
 
-			if ok, err := confirm.HandleConfirm(localCtx, event, ui.AskYesNo); !ok {
-				return nil
+			conv.journal.track(event)
+			if ok, err := handleConfirm(localCtx, client, confirmer, event, ui.AskYesNo); !ok {
+				return ui.Err()
 			} else if err != nil && localCtx.Err() == nil {
 				return err
 			}
@@ -276,7 +387,12 @@ func Run(ctx context.Context, tool string, opts ...RunOptions) error {
 			text = interrupted
 		}
 
-		ui.Finished(text())
+		finished := text()
+		status.begin(nil, conv.session.Name)
+		ui.Finished(finished)
+		if observer != nil {
+			observer.Done(run)
+		}
 
 		if opt.SaveChatStateFile != "" {
 			if run.State() == gptscript.Finished {
@@ -286,11 +402,42 @@ func Run(ctx context.Context, tool string, opts ...RunOptions) error {
 			}
 		}
 
+		if run.State() == gptscript.Error && localCtx.Err() == nil {
+			if delay, ok := retries.next(run.Err()); ok {
+				if observer == nil {
+					fmt.Println(color.YellowString("%v, retrying in %v", run.Err(), delay))
+				}
+				select {
+				case <-localCtx.Done():
+				case <-time.After(delay):
+					// A retried attempt isn't a turn of its own, the turn keeps its journal so undo also
+					// restores the files the failed attempts changed
+					if next, err := run.NextChat(localCtx, submitted); err == nil {
+						run = next
+						continue
+					}
+				}
+			}
+		}
+
+		if err := conv.record(run, submitted, finished); err != nil {
+			fmt.Fprintln(errOut, ErrorStyle.Sprintf("failed to save session %s: %v", conv.session.Name, err))
+		}
+		if opt.Transcript != "" {
+			if err := conv.export(opt.Transcript); err != nil && observer == nil {
+				fmt.Println(ErrorStyle.Sprintf("failed to write transcript: %v", err))
+			}
+		}
+
 		for {
 			if run != nil && run.State().IsTerminal() {
 				if errors.Is(localCtx.Err(), context.Canceled) {
+				} else if run.Err() != nil && opt.Headless {
+					return run.Err()
 				} else if run.Err() != nil {
-					fmt.Println(color.RedString("%v", run.Err()))
+					if observer == nil {
+						fmt.Println(ErrorStyle.Sprintf("%v", run.Err()))
+					}
 				} else {
 					return nil
 				}
@@ -300,26 +447,108 @@ func Run(ctx context.Context, tool string, opts ...RunOptions) error {
 			cancel()
 			localCtx, cancel = signal.NotifyContext(ctx, os.Interrupt)
 
-			line, ok := ui.Prompt(getCurrentToolName(run))
+			line, ok := promptLine(ui, commands, getCurrentToolName(run))
 			if !ok {
 				return nil
 			}
 
 			input = line
+			submitted = line
+			retries.reset()
 			ui.Progress(render(input, nil))
+			if observer != nil {
+				observer.Input(input)
+			}
 
-			run, err = run.NextChat(localCtx, input)
+			conv.begin(opt.Workspace)
+			var next *gptscript.Run
+			if conv.pending != nil {
+				next, err = startRun(localCtx, input, *conv.pending)
+			} else {
+				next, err = run.NextChat(localCtx, input)
+			}
 			if err != nil {
-				fmt.Println(color.RedString("%v", run.Err()))
-				run = nil
+				fmt.Fprintln(errOut, ErrorStyle.Sprintf("%v", err))
 				continue
 			}
+			run = next
+			conv.pending = nil
 
 			break
 		}
 	}
 }
 
+// setupChat wraps the confirmer of a run to record its decisions and returns the chat commands available
+// at the prompt.
+func setupChat(confirmer Confirmer, conv *conversation, ui userInterface) (Confirmer, chatCommands) {
+	commands := chatCommands{}
+	// Check for the built-in confirmer before it is wrapped
+	confirm, isConfirm := confirmer.(*Confirm)
+
+	confirmer = recordedConfirmer{
+		Confirmer: confirmer,
+		conv:      conv,
+	}
+	if observer, ok := ui.(runObserver); ok {
+		// Commands print to stdout, which is reserved for the observer
+		return observedConfirmer{
+			Confirmer: confirmer,
+			observer:  observer,
+		}, commands
+	}
+
+	commands["fork"] = forkCommand(conv)
+	commands["branches"] = branchesCommand(conv)
+	commands["switch"] = switchCommand(conv)
+	commands["retry"] = retryCommand(conv)
+	redraw := func(transcript []transcriptTurn) {
+		// Clear the screen and print the turns that are left
+		fmt.Print("\033[H\033[2J")
+		for _, turn := range transcript {
+			fmt.Println(strings.TrimSuffix(turn.Rendered, "\n"))
+		}
+	}
+	commands["undo"] = undoCommand(conv, redraw)
+	commands["history"] = historyCommand(conv, ui, redraw)
+	commands["calls"] = callsCommand(conv, ui, redraw)
+	commands["export"] = exportCommand(conv)
+	if isConfirm {
+		commands["trust"] = trustCommand(confirm)
+	}
+	return confirmer, commands
+}
+
+func newUserInterface(tool string, opt RunOptions) (userInterface, error) {
+	if opt.JSONOutput {
+		return newJSONDisplay(os.Stdin, os.Stdout), nil
+	}
+	if opt.Headless {
+		return newHeadlessDisplay(os.Stdout), nil
+	}
+	return newDisplay(tool)
+}
+
+// promptLine prompts until the user enters a line that is not a chat command.
+func promptLine(ui userInterface, commands chatCommands, text string) (string, bool) {
+	for {
+		line, ok := ui.Prompt(text)
+		if !ok {
+			return "", false
+		}
+
+		submit, handled, err := commands.handle(line)
+		if !handled {
+			return line, true
+		}
+		if err != nil {
+			fmt.Println(ErrorStyle.Sprintf("%v", err))
+		} else if submit != "" {
+			return submit, true
+		}
+	}
+}
+
 func splitAtTerm(line string, width int) string {
 	var (
 		buf    = &strings.Builder{}
@@ -348,7 +577,7 @@ func renderDeferred(input string, parent *gptscript.CallFrame, calls map[string]
 	buf := &strings.Builder{}
 
 	if input != "" {
-		buf.WriteString(color.GreenString(splitAtTerm("> "+input+"\n", pterm.GetTerminalWidth())))
+		buf.WriteString(InputEchoStyle.Sprint(splitAtTerm("> "+input+"\n", pterm.GetTerminalWidth())))
 		buf.WriteString("\n")
 	}
 
@@ -360,24 +589,31 @@ func renderDeferred(input string, parent *gptscript.CallFrame, calls map[string]
 }
 
 func render(input string, run *gptscript.Run) func() string {
+	var calls gptscript.CallFrames
+	if run != nil {
+		calls = run.Calls()
+	}
+	return renderCalls(input, calls)
+}
+
+func renderCalls(input string, calls gptscript.CallFrames) func() string {
 	var (
 		content string
 		parent  *gptscript.CallFrame
-		calls   map[string]gptscript.CallFrame
 	)
 
-	if run != nil {
-		call, ok := run.ParentCallFrame()
-		if ok {
-			parent = &call
-			calls = run.Calls()
-		}
+	if len(calls) > 0 {
+		call := calls.ParentCallFrame()
+		parent = &call
 	}
 
+	generation := styleGeneration.Load()
 	return func() string {
-		if content != "" {
+		// Render again if the terminal was resized
+		if content != "" && generation == styleGeneration.Load() {
 			return content
 		}
+		generation = styleGeneration.Load()
 		content = renderDeferred(input, parent, calls)
 		return content
 	}
@@ -410,7 +646,11 @@ func printToolCall(out *strings.Builder, toolCall string) {
 
 	if buf.Len() > 0 {
 		out.WriteString("\n")
-		out.WriteString(BoxStyle.Render("Call Arguments:\n\n" + buf.String()))
+		args := buf.String()
+		if ToolCallStyle != nil {
+			args = ToolCallStyle.Sprint(args)
+		}
+		out.WriteString(renderBox("Call Arguments:\n\n" + args))
 	}
 }
 
@@ -420,7 +660,7 @@ func printCall(buf *strings.Builder, calls map[string]gptscript.CallFrame, call
 	}
 
 	if call.DisplayText != "" {
-		s, err := MarkdownRender.Render(call.DisplayText)
+		s, err := renderMarkdown(call.DisplayText)
 		if err == nil {
 			buf.WriteString(s)
 		}
@@ -444,9 +684,9 @@ func printCall(buf *strings.Builder, calls map[string]gptscript.CallFrame, call
 		content, toolCall, _ := strings.Cut(output.Content, ToolCallHeader)
 		if content != "" {
 			if strings.HasPrefix(call.Tool.Instructions, "#!") {
-				buf.WriteString(BoxStyle.Render(strings.TrimSpace(content)))
+				buf.WriteString(renderBox(strings.TrimSpace(content)))
 			} else {
-				s, err := MarkdownRender.Render(content)
+				s, err := renderMarkdown(content)
 				if err == nil {
 					buf.WriteString(s)
 				} else {
@@ -471,6 +711,9 @@ func printCall(buf *strings.Builder, calls map[string]gptscript.CallFrame, call
 }
 
 func getCurrentToolName(run *gptscript.Run) string {
+	if run == nil {
+		return ""
+	}
 	toolName := run.RespondingTool().Name
 	if toolName == "" {
 		return ""
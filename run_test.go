@@ -1,7 +1,13 @@
 package tui
 
 import (
+	"io"
+	"strings"
 	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/pterm/pterm"
 )
 
 func TestSplitAtTerm(t *testing.T) {
@@ -70,3 +76,57 @@ func TestSplitAtTerm(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderCallsResize(t *testing.T) {
+	defer func() {
+		_ = resizeStyles(pterm.GetTerminalWidth())
+	}()
+
+	calls := gptscript.CallFrames{
+		"1": {
+			CallContext: gptscript.CallContext{ID: "1"},
+			Output:      []gptscript.Output{{Content: strings.Repeat("word ", 30)}},
+		},
+	}
+
+	if err := resizeStyles(200); err != nil {
+		t.Fatal(err)
+	}
+	text := renderCalls("", calls)
+	wide := text()
+
+	if err := resizeStyles(40); err != nil {
+		t.Fatal(err)
+	}
+	narrow := text()
+	if strings.Count(narrow, "\n") <= strings.Count(wide, "\n") {
+		t.Fatalf("expected more lines after resizing, got %q and %q", wide, narrow)
+	}
+}
+
+func TestSetupChatTrustCommand(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	confirm, err := NewConfirmWithOptions("test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ui := newHeadlessDisplay(io.Discard)
+	defer ui.Close()
+
+	confirmer, commands := setupChat(confirm, &conversation{}, ui)
+	if _, ok := confirmer.(recordedConfirmer); !ok {
+		t.Fatalf("expected decisions to be recorded, got %T", confirmer)
+	}
+	if _, handled, err := commands.handle("/trust list"); !handled || err != nil {
+		t.Fatalf("expected /trust list to be handled, got %v, %v", handled, err)
+	}
+
+	_, commands = setupChat(confirm, &conversation{}, newJSONDisplay(strings.NewReader(""), io.Discard))
+	if len(commands) != 0 {
+		t.Fatalf("expected no commands in JSON mode, got %d", len(commands))
+	}
+}
@@ -0,0 +1,203 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/adrg/xdg"
+)
+
+const defaultAppName = "gptscript-tui"
+
+var validSessionName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Session is a named conversation with a tool that can be resumed later.
+type Session struct {
+	Name        string    `json:"name"`
+	Tool        string    `json:"tool"`
+	Parent      string    `json:"parent,omitempty"`
+	ChatState   string    `json:"chatState"`
+	LastMessage string    `json:"lastMessage,omitempty"`
+	Turns       []Turn    `json:"turns,omitempty"`
+	Updated     time.Time `json:"updated"`
+}
+
+// Turn is the chat state after a completed turn of a session, so the session can be forked from it.
+type Turn struct {
+	Input     string    `json:"input"`
+	ChatState string    `json:"chatState"`
+	Time      time.Time `json:"time"`
+}
+
+// savedTurn is a Turn as it is saved. Every chat state contains the whole conversation before it, so only
+// the part that differs from the chat state of the previous turn is saved.
+type savedTurn struct {
+	Input string `json:"input"`
+	// Shared is the length of the chat state of the previous turn that this chat state starts with
+	Shared    int       `json:"shared,omitempty"`
+	ChatState string    `json:"chatState"`
+	Time      time.Time `json:"time"`
+}
+
+func (s Session) MarshalJSON() ([]byte, error) {
+	type session Session
+	saved := struct {
+		session
+		Turns []savedTurn `json:"turns,omitempty"`
+	}{
+		session: session(s),
+	}
+
+	var previous string
+	for _, turn := range s.Turns {
+		shared := commonPrefixLength(previous, turn.ChatState)
+		saved.Turns = append(saved.Turns, savedTurn{
+			Input:     turn.Input,
+			Shared:    shared,
+			ChatState: turn.ChatState[shared:],
+			Time:      turn.Time,
+		})
+		previous = turn.ChatState
+	}
+	return json.Marshal(saved)
+}
+
+func (s *Session) UnmarshalJSON(data []byte) error {
+	type session Session
+	var saved struct {
+		session
+		Turns []savedTurn `json:"turns,omitempty"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+
+	*s = Session(saved.session)
+	s.Turns = nil
+	var previous string
+	for i, turn := range saved.Turns {
+		if turn.Shared < 0 || turn.Shared > len(previous) {
+			return fmt.Errorf("invalid chat state of turn %d", i+1)
+		}
+		chatState := previous[:turn.Shared] + turn.ChatState
+		s.Turns = append(s.Turns, Turn{
+			Input:     turn.Input,
+			ChatState: chatState,
+			Time:      turn.Time,
+		})
+		previous = chatState
+	}
+	return nil
+}
+
+// commonPrefixLength returns the length of the prefix a and b share, ending at a rune boundary so the rest
+// is valid UTF-8.
+func commonPrefixLength(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	for i > 0 && i < len(b) && !utf8.RuneStart(b[i]) {
+		i--
+	}
+	return i
+}
+
+func sessionFile(appName, tool, name string) (string, error) {
+	if !validSessionName.MatchString(name) {
+		return "", fmt.Errorf("invalid session name %q, only letters, numbers, '.', '_' and '-' are allowed", name)
+	}
+	if appName == "" {
+		appName = defaultAppName
+	}
+	return xdg.CacheFile(fmt.Sprintf("%s/sessions/%s/%s.json", appName, id(tool), name))
+}
+
+// LoadSession returns the session with the given name, or false if it does not exist.
+func LoadSession(appName, tool, name string) (Session, bool, error) {
+	var session Session
+
+	file, err := sessionFile(appName, tool, name)
+	if err != nil {
+		return session, false, err
+	}
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return session, false, nil
+	} else if err != nil {
+		return session, false, err
+	}
+
+	if err := json.Unmarshal(data, &session); err != nil {
+		return session, false, fmt.Errorf("failed to read session %s: %w", name, err)
+	}
+	return session, true, nil
+}
+
+func SaveSession(appName string, session Session) error {
+	file, err := sessionFile(appName, session.Tool, session.Name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	// Write and rename so an interrupted save doesn't lose the session
+	if err := os.WriteFile(file+".tmp", data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+func DeleteSession(appName, tool, name string) error {
+	file, err := sessionFile(appName, tool, name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ListSessions returns the sessions of a tool, most recently updated first.
+func ListSessions(appName, tool string) ([]Session, error) {
+	// Any valid name will do to find the directory
+	file, err := sessionFile(appName, tool, "x")
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(file), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Session
+	for _, file := range files {
+		session, ok, err := LoadSession(appName, tool, strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, session)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Updated.After(result[j].Updated)
+	})
+	return result, nil
+}
@@ -0,0 +1,205 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+func TestSessions(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	if _, ok, err := LoadSession("", "tool.gpt", "missing"); err != nil || ok {
+		t.Fatalf("expected no session, got %v, %v", ok, err)
+	}
+	if _, _, err := LoadSession("", "tool.gpt", "../escape"); err == nil {
+		t.Fatal("expected invalid name error")
+	}
+
+	now := time.Now()
+	for _, s := range []Session{
+		{Name: "old", Tool: "tool.gpt", ChatState: "1", Updated: now.Add(-time.Hour)},
+		{Name: "new", Tool: "tool.gpt", ChatState: "2", LastMessage: "hi", Updated: now},
+		{Name: "other", Tool: "other.gpt", Updated: now},
+	} {
+		if err := SaveSession("", s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sessions, err := ListSessions("", "tool.gpt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].Name != "new" || sessions[0].LastMessage != "hi" || sessions[1].Name != "old" {
+		t.Fatalf("unexpected sessions %+v", sessions)
+	}
+
+	for range 2 {
+		if err := DeleteSession("", "tool.gpt", "new"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sessions, err = ListSessions("", "tool.gpt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("expected one session, got %d", len(sessions))
+	}
+}
+
+func TestSessionTurnsSaveOnlyChanges(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	session := Session{Name: "long", Tool: "tool.gpt"}
+	chatState := `{"messages":[`
+	for i := range 50 {
+		// The states of two turns share a prefix that ends inside a multibyte rune, é and è
+		chatState += fmt.Sprintf(`"é message %d with some text",`, i)
+		session.Turns = append(session.Turns, Turn{
+			Input:     fmt.Sprint(i),
+			ChatState: chatState + `"è"]}`,
+		})
+	}
+	session.ChatState = session.Turns[len(session.Turns)-1].ChatState
+
+	if err := SaveSession("", session); err != nil {
+		t.Fatal(err)
+	}
+	file, err := sessionFile("", "tool.gpt", "long")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(file); err != nil {
+		t.Fatal(err)
+	} else if full := len(session.Turns) * len(session.ChatState) / 2; info.Size() > int64(full/4) {
+		t.Fatalf("expected only the changes to be saved, the session is %d bytes, all chat states are about %d", info.Size(), full)
+	}
+
+	loaded, ok, err := LoadSession("", "tool.gpt", "long")
+	if err != nil || !ok {
+		t.Fatalf("failed to load session: %v, %v", ok, err)
+	}
+	if !reflect.DeepEqual(loaded.Turns, session.Turns) {
+		t.Fatalf("expected the chat states of the turns to be restored, got %+v", loaded.Turns)
+	}
+}
+
+func TestConversationFork(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	conv := &conversation{
+		session: Session{
+			Name: "main",
+			Tool: "tool.gpt",
+			Turns: []Turn{
+				{Input: "one", ChatState: "state-1"},
+				{Input: "two", ChatState: "state-2"},
+			},
+		},
+	}
+
+	if err := conv.fork(3, ""); err == nil {
+		t.Fatal("expected error forking from a missing turn")
+	}
+	if err := conv.fork(1, ""); err != nil {
+		t.Fatal(err)
+	}
+	if conv.session.Name != "main-1" || conv.session.Parent != "main" || len(conv.session.Turns) != 1 {
+		t.Fatalf("unexpected branch %+v", conv.session)
+	}
+	if conv.pending == nil || *conv.pending != "state-1" {
+		t.Fatalf("expected pending state-1, got %v", conv.pending)
+	}
+
+	conv.pending = nil
+	if err := conv.fork(1, "main-1"); err == nil {
+		t.Fatal("expected error forking to an existing session")
+	}
+	if err := conv.switchTo("missing"); err == nil {
+		t.Fatal("expected error switching to a missing session")
+	}
+	if err := conv.switchTo("main-1"); err != nil || *conv.pending != "state-1" {
+		t.Fatalf("failed to switch: %v", err)
+	}
+}
+
+func TestConversationUndo(t *testing.T) {
+	workspace := t.TempDir()
+	existing := filepath.Join(workspace, "existing.txt")
+	created := filepath.Join(workspace, "created.txt")
+	if err := os.WriteFile(existing, []byte("before"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv := &conversation{
+		initial: "initial",
+		session: Session{
+			Tool:  "tool.gpt",
+			Turns: []Turn{{Input: "one", ChatState: "state-1"}},
+		},
+		transcript: []transcriptTurn{{Input: "one"}},
+	}
+	conv.begin(workspace)
+	for _, filename := range []string{existing, created, "/outside"} {
+		event := sysEvent("#!sys.write", `{"filename": "`+filename+`", "content": "after"}`)
+		event.Call.Type = gptscript.EventTypeCallConfirm
+		conv.journal.track(event)
+	}
+	if err := os.WriteFile(existing, []byte("after"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(created, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := conv.undo(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored != 2 {
+		t.Fatalf("expected 2 restored files, got %d", restored)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "before" {
+		t.Fatalf("expected existing file to be restored, got %q", data)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Fatalf("expected created file to be removed, got %v", err)
+	}
+	if *conv.pending != "initial" || len(conv.session.Turns) != 0 || len(conv.transcript) != 0 {
+		t.Fatalf("unexpected state after undo %+v", conv)
+	}
+
+	if _, err := conv.undo(false); err == nil {
+		t.Fatal("expected nothing to undo")
+	}
+}
+
+func TestConversationUndoFailedFirstTurn(t *testing.T) {
+	// Resumed from a chat state without a session
+	conv := &conversation{
+		initial:    "resumed",
+		failed:     true,
+		transcript: []transcriptTurn{{Input: "one", Error: "failed"}},
+	}
+
+	if _, err := conv.undo(false); err != nil {
+		t.Fatal(err)
+	}
+	if conv.pending == nil || *conv.pending != "resumed" {
+		t.Fatalf("expected to continue from the resumed chat state, got %v", conv.pending)
+	}
+}
@@ -0,0 +1,427 @@
+package tui
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Risk is the risk tier of a shell command.
+type Risk int
+
+const (
+	RiskReadOnly Risk = iota
+	RiskMutating
+	RiskNetwork
+	RiskDestructive
+)
+
+func (r Risk) String() string {
+	switch r {
+	case RiskReadOnly:
+		return "read-only"
+	case RiskMutating:
+		return "mutating"
+	case RiskNetwork:
+		return "network"
+	}
+	return "destructive/privileged"
+}
+
+// shellSegment is a single simple command of a shell command line. Pipelines, lists and subshells are
+// split into one segment per command.
+type shellSegment struct {
+	args []string
+	// redirects are the files output is redirected to
+	redirects []string
+}
+
+func (s shellSegment) String() string {
+	return strings.Join(s.args, " ")
+}
+
+var (
+	readOnlyCommands = []string{
+		"ls", "cat", "head", "tail", "grep", "egrep", "fgrep", "rg", "ag", "find", "pwd", "echo", "printf",
+		"wc", "sort", "uniq", "diff", "cmp", "which", "whereis", "type", "file", "stat", "du", "df", "printenv",
+		"date", "whoami", "id", "uname", "hostname", "tree", "less", "more", "jq", "yq", "awk", "cut", "tr",
+		"basename", "dirname", "realpath", "readlink", "test", "[", "true", "false", "seq", "sha256sum",
+		"sha1sum", "md5sum", "base64", "column", "nl", "tac", "rev", "fold", "ps", "free", "uptime", "sed",
+	}
+	networkCommands = []string{
+		"curl", "wget", "ssh", "scp", "sftp", "rsync", "nc", "ncat", "telnet", "ftp", "ping", "dig",
+		"nslookup", "host", "http", "https",
+	}
+	destructiveCommands = []string{
+		"rm", "rmdir", "dd", "shred", "fdisk", "parted", "kill", "killall", "pkill", "shutdown", "reboot",
+		"halt", "poweroff", "chmod", "chown", "chgrp", "mount", "umount", "truncate", "systemctl", "launchctl",
+		"crontab", "iptables", "passwd", "useradd", "userdel", "sudo", "su", "doas",
+	}
+	// wrapperCommands run the command given in their arguments
+	wrapperCommands = []string{"env", "time", "nice", "nohup", "command", "xargs", "timeout", "exec"}
+	shellCommands   = []string{"sh", "bash", "zsh", "dash", "ksh"}
+	installCommands = []string{
+		"npm", "yarn", "pnpm", "pip", "pip3", "go", "cargo", "gem", "brew", "apt", "apt-get", "yum", "dnf",
+		"apk", "docker", "podman",
+	}
+	installSubcommands = []string{"install", "i", "add", "get", "download", "pull", "push", "fetch", "update"}
+	gitReadOnly        = []string{
+		"status", "log", "diff", "show", "rev-parse", "ls-files", "ls-tree", "blame", "describe", "shortlog",
+		"grep", "cat-file", "reflog",
+	}
+	gitNetwork = []string{"clone", "fetch", "pull", "push", "ls-remote", "submodule"}
+)
+
+// Risk returns the highest risk of the command of this segment.
+func (s shellSegment) Risk() Risk {
+	risk := commandRisk(s.args)
+	for _, redirect := range s.redirects {
+		if !slices.Contains([]string{"/dev/null", "/dev/stdout", "/dev/stderr"}, redirect) {
+			risk = max(risk, RiskMutating)
+		}
+	}
+	return risk
+}
+
+// unwrap returns the arguments of the command that is run, without variable assignments and wrapper commands.
+func unwrap(args []string) []string {
+	for {
+		for len(args) > 0 && isAssignment(args[0]) {
+			args = args[1:]
+		}
+		if len(args) == 0 || !slices.Contains(wrapperCommands, filepath.Base(args[0])) {
+			return args
+		}
+
+		name := filepath.Base(args[0])
+		args = args[1:]
+		for len(args) > 0 && (strings.HasPrefix(args[0], "-") || (name == "timeout" && isDuration(args[0]))) {
+			args = args[1:]
+		}
+	}
+}
+
+// shellScript returns the script a shell or eval runs. ok is false for other commands and script is empty
+// when a shell runs a script file or reads its commands from stdin.
+func shellScript(args []string) (script string, ok bool) {
+	args = unwrap(args)
+	if len(args) == 0 {
+		return "", false
+	}
+
+	name := filepath.Base(args[0])
+	if name == "eval" {
+		return strings.Join(args[1:], " "), true
+	}
+	if !slices.Contains(shellCommands, name) {
+		return "", false
+	}
+	if i := slices.Index(args, "-c"); i >= 0 && i+1 < len(args) {
+		return args[i+1], true
+	}
+	return "", true
+}
+
+func commandRisk(args []string) Risk {
+	args = unwrap(args)
+	if len(args) == 0 {
+		return RiskReadOnly
+	}
+
+	if script, ok := shellScript(args); ok {
+		if script == "" {
+			return RiskMutating
+		}
+		return shellRisk(parseShell(script))
+	}
+
+	name := filepath.Base(args[0])
+	switch {
+	case name == "git":
+		return gitRisk(args[1:])
+	case name == "find":
+		if slices.Contains(args, "-delete") {
+			return RiskDestructive
+		}
+		for _, exec := range []string{"-exec", "-execdir", "-ok", "-okdir"} {
+			if i := slices.Index(args, exec); i >= 0 && i+1 < len(args) {
+				return max(RiskMutating, commandRisk(args[i+1:]))
+			}
+		}
+		return RiskReadOnly
+	case name == "sed":
+		for _, arg := range args[1:] {
+			if strings.HasPrefix(arg, "-i") || arg == "--in-place" {
+				return RiskMutating
+			}
+		}
+		return RiskReadOnly
+	case name == "sort" && slices.Contains(args, "-o"):
+		return RiskMutating
+	case strings.HasPrefix(name, "mkfs") || slices.Contains(destructiveCommands, name):
+		return RiskDestructive
+	case slices.Contains(networkCommands, name):
+		return RiskNetwork
+	case slices.Contains(installCommands, name):
+		if len(args) > 1 && slices.Contains(installSubcommands, args[1]) {
+			return RiskNetwork
+		}
+		return RiskMutating
+	case slices.Contains(readOnlyCommands, name):
+		return RiskReadOnly
+	}
+
+	return RiskMutating
+}
+
+func gitRisk(args []string) Risk {
+	// Skip global options
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		if args[0] == "-C" || args[0] == "-c" {
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return RiskReadOnly
+	}
+
+	var (
+		sub   = args[0]
+		flags = args[1:]
+	)
+	switch {
+	case sub == "push" && (slices.Contains(flags, "-f") || slices.Contains(flags, "--force") ||
+		slices.Contains(flags, "--force-with-lease")):
+		return RiskDestructive
+	case sub == "reset" && slices.Contains(flags, "--hard"), sub == "clean",
+		sub == "branch" && slices.Contains(flags, "-D"):
+		return RiskDestructive
+	case slices.Contains(gitNetwork, sub):
+		return RiskNetwork
+	case slices.Contains(gitReadOnly, sub):
+		return RiskReadOnly
+	case sub == "branch" || sub == "tag" || sub == "remote" || sub == "stash":
+		for _, flag := range flags {
+			if !slices.Contains([]string{"-v", "-vv", "-a", "-l", "--list", "list"}, flag) {
+				return RiskMutating
+			}
+		}
+		return RiskReadOnly
+	}
+
+	return RiskMutating
+}
+
+func shellRisk(segments []shellSegment) Risk {
+	risk := RiskReadOnly
+	for _, segment := range segments {
+		risk = max(risk, segment.Risk())
+	}
+	return risk
+}
+
+func isAssignment(arg string) bool {
+	name, _, ok := strings.Cut(arg, "=")
+	if !ok || name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDuration(arg string) bool {
+	return strings.TrimRight(strings.TrimLeft(arg, "0123456789."), "smhd") == ""
+}
+
+// parseShell splits a shell command line into its simple commands. It understands quoting, pipes, lists,
+// subshells, command substitution and redirections. It is not a full shell parser, it only needs to be good
+// enough to know which commands a command line will run.
+func parseShell(command string) []shellSegment {
+	var (
+		segments    []shellSegment
+		current     shellSegment
+		word        strings.Builder
+		inWord      bool
+		redirect    bool
+		redirectOut bool
+		runes       = []rune(command)
+	)
+
+	flushWord := func() {
+		if !inWord {
+			return
+		}
+		w := word.String()
+		word.Reset()
+		inWord = false
+		if redirect {
+			if redirectOut {
+				current.redirects = append(current.redirects, w)
+			}
+			redirect = false
+			return
+		}
+		current.args = append(current.args, w)
+	}
+	flushSegment := func() {
+		flushWord()
+		if len(current.args) > 0 || len(current.redirects) > 0 {
+			segments = append(segments, current)
+		}
+		current = shellSegment{}
+	}
+	substitute := func(inner string) {
+		segments = append(segments, parseShell(inner)...)
+	}
+	peek := func(i int) rune {
+		if i < len(runes) {
+			return runes[i]
+		}
+		return 0
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\':
+			inWord = true
+			if i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+			}
+		case r == '\'':
+			inWord = true
+			end := indexRune(runes, i+1, '\'')
+			word.WriteString(string(runes[i+1 : end]))
+			i = end
+		case r == '"':
+			inWord = true
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+				switch {
+				case runes[i] == '\\' && strings.ContainsRune("\"\\$`", peek(i+1)):
+					i++
+					word.WriteRune(runes[i])
+				case runes[i] == '$' && peek(i+1) == '(':
+					end := closingParen(runes, i+1)
+					substitute(string(runes[i+2 : end]))
+					word.WriteString(string(runes[i:min(end+1, len(runes))]))
+					i = end
+				case runes[i] == '`':
+					end := indexRune(runes, i+1, '`')
+					substitute(string(runes[i+1 : end]))
+					word.WriteString(string(runes[i:min(end+1, len(runes))]))
+					i = end
+				default:
+					word.WriteRune(runes[i])
+				}
+			}
+		case r == '$' && peek(i+1) == '(':
+			inWord = true
+			end := closingParen(runes, i+1)
+			if peek(i+2) != '(' {
+				substitute(string(runes[i+2 : end]))
+			}
+			word.WriteString(string(runes[i:min(end+1, len(runes))]))
+			i = end
+		case r == '`':
+			inWord = true
+			end := indexRune(runes, i+1, '`')
+			substitute(string(runes[i+1 : end]))
+			word.WriteString(string(runes[i:min(end+1, len(runes))]))
+			i = end
+		case r == '(' && !inWord:
+			flushSegment()
+			end := closingParen(runes, i)
+			substitute(string(runes[i+1 : end]))
+			i = end
+		case (r == '{' || r == '}') && !inWord && (unicode.IsSpace(peek(i+1)) || peek(i+1) == ';' || peek(i+1) == 0):
+			// Command group
+			flushSegment()
+		case r == ')' && !inWord:
+			flushSegment()
+		case r == '#' && !inWord:
+			i = indexRune(runes, i, '\n') - 1
+		case r == '\n' || r == ';' || r == '|':
+			flushSegment()
+		case r == '&':
+			if peek(i+1) == '>' {
+				flushWord()
+				i++
+				if peek(i+1) == '>' {
+					i++
+				}
+				redirect, redirectOut = true, true
+			} else {
+				flushSegment()
+			}
+		case r == '>' || r == '<':
+			if inWord && strings.Trim(word.String(), "0123456789") == "" {
+				// File descriptor, such as 2>
+				word.Reset()
+				inWord = false
+			} else {
+				flushWord()
+			}
+			if peek(i+1) == r {
+				i++
+			}
+			if peek(i+1) == '&' {
+				// Duplicating a file descriptor, such as 2>&1
+				for i++; unicode.IsDigit(peek(i+1)) || peek(i+1) == '-'; i++ {
+				}
+				continue
+			}
+			redirect, redirectOut = true, r == '>'
+		case unicode.IsSpace(r):
+			flushWord()
+		default:
+			inWord = true
+			word.WriteRune(r)
+		}
+	}
+	flushSegment()
+
+	return segments
+}
+
+// indexRune returns the index of r at or after start, or len(runes) if not found.
+func indexRune(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return len(runes)
+}
+
+// closingParen returns the index of the parenthesis closing the one at open, or len(runes) if not found.
+func closingParen(runes []rune, open int) int {
+	var (
+		depth int
+		quote rune
+	)
+	for i := open; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(runes)
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseShell(t *testing.T) {
+	testCases := []struct {
+		name    string
+		command string
+		want    []string
+	}{
+		{name: "Simple", command: "ls -la", want: []string{"ls -la"}},
+		{name: "Pipe", command: "cat a.txt | grep foo", want: []string{"cat a.txt", "grep foo"}},
+		{name: "List", command: "make && make test; echo done || true", want: []string{"make", "make test", "echo done", "true"}},
+		{name: "Quotes", command: `echo "a && b" 'c | d'`, want: []string{"echo a && b c | d"}},
+		{name: "Subshell", command: "(cd dir && rm -rf x)", want: []string{"cd dir", "rm -rf x"}},
+		{name: "Substitution", command: "echo $(rm -rf /)", want: []string{"rm -rf /", "echo $(rm -rf /)"}},
+		{name: "Redirect", command: "echo hi > out.txt 2>&1", want: []string{"echo hi"}},
+		{name: "Background", command: "sleep 1 & curl x", want: []string{"sleep 1", "curl x"}},
+		{name: "Empty", command: "  ", want: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, segment := range parseShell(tc.command) {
+				got = append(got, segment.String())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseShell(%q) = %q, want %q", tc.command, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestShellRisk(t *testing.T) {
+	testCases := []struct {
+		command string
+		want    Risk
+	}{
+		{command: "ls -la | grep foo", want: RiskReadOnly},
+		{command: "git status && git diff", want: RiskReadOnly},
+		{command: "echo hi > /dev/null", want: RiskReadOnly},
+		{command: "echo hi > out.txt", want: RiskMutating},
+		{command: "sed -i s/a/b/ file", want: RiskMutating},
+		{command: "make build", want: RiskMutating},
+		{command: "curl https://example.com", want: RiskNetwork},
+		{command: "git pull", want: RiskNetwork},
+		{command: "npm install", want: RiskNetwork},
+		{command: "ls; rm -rf /", want: RiskDestructive},
+		{command: "sudo ls", want: RiskDestructive},
+		{command: "FOO=bar xargs rm", want: RiskDestructive},
+		{command: `bash -c "git push --force"`, want: RiskDestructive},
+		{command: "find . -name '*.tmp' -delete", want: RiskDestructive},
+		{command: "echo `rm x`", want: RiskDestructive},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.command, func(t *testing.T) {
+			if got := shellRisk(parseShell(tc.command)); got != tc.want {
+				t.Errorf("shellRisk(%q) = %v, want %v", tc.command, got, tc.want)
+			}
+		})
+	}
+}
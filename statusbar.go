@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/pterm/pterm"
+)
+
+// statusBar is implemented by user interfaces that keep a status line under the output of a turn, or above
+// the prompt between turns.
+type statusBar interface {
+	SetStatus(status func() string)
+}
+
+// turnStatus is the state shown in the status bar. It is updated by the run loop and read while painting,
+// run is nil between turns.
+type turnStatus struct {
+	lock         sync.Mutex
+	run          *gptscript.Run
+	start        time.Time
+	session      string
+	defaultModel string
+	workspace    string
+}
+
+func (s *turnStatus) begin(run *gptscript.Run, session string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.run = run
+	s.start = time.Now()
+	s.session = session
+}
+
+func (s *turnStatus) String() string {
+	s.lock.Lock()
+	run, start, session := s.run, s.start, s.session
+	s.lock.Unlock()
+
+	var parts []string
+	if run == nil {
+		if s.defaultModel != "" {
+			parts = append(parts, s.defaultModel)
+		}
+		parts = append(parts, "idle")
+	} else {
+		var (
+			calls  = run.Calls()
+			parent = calls.ParentCallFrame()
+			active int
+		)
+		for _, call := range calls {
+			if !call.Start.IsZero() && call.End.IsZero() {
+				active++
+			}
+		}
+
+		if name := parent.ToolName; name != "" {
+			parts = append(parts, "@"+name)
+		}
+		if model := callModel(parent, s.defaultModel); model != "" {
+			parts = append(parts, model)
+		}
+		parts = append(parts, time.Since(start).Truncate(time.Second).String())
+		if active == 1 {
+			parts = append(parts, "1 active call")
+		} else {
+			parts = append(parts, fmt.Sprintf("%d active calls", active))
+		}
+	}
+	if s.workspace != "" {
+		parts = append(parts, shortPath(s.workspace))
+	}
+	if session != "" {
+		parts = append(parts, "autosave: "+session)
+	} else {
+		parts = append(parts, "not saved")
+	}
+
+	line := strings.Join(parts, " · ")
+	if width := pterm.GetTerminalWidth() - 1; width > 0 && len([]rune(line)) > width {
+		line = string([]rune(line)[:width])
+	}
+	return color.New(color.ReverseVideo).Sprint(line)
+}
+
+func shortPath(p string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return p
+	}
+	if rel, err := filepath.Rel(home, p); err == nil && withinDir(p, home) {
+		return filepath.Join("~", rel)
+	}
+	return p
+}
+
+// withStatus puts the status line under content, keeping the last lines of content that fit in height. Without
+// content the status line ends with a newline so the prompt goes under it.
+func withStatus(content, status string, height int) string {
+	if status == "" {
+		return lastLines(content, height)
+	}
+	if content == "" {
+		return status + "\n"
+	}
+	return lastLines(content, height-1) + "\n" + status
+}
+
+func lastLines(content string, n int) string {
+	lines := strings.Split(content, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-max(n, 0):]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pterm/pterm"
+)
+
+func TestTurnStatusIdle(t *testing.T) {
+	status := &turnStatus{
+		defaultModel: "gpt-4o",
+		workspace:    "/work",
+	}
+
+	status.begin(nil, "")
+	if got := pterm.RemoveColorFromString(status.String()); got != "gpt-4o · idle · /work · not saved" {
+		t.Fatalf("unexpected idle status %q", got)
+	}
+
+	status.begin(nil, "chat")
+	if got := pterm.RemoveColorFromString(status.String()); !strings.HasSuffix(got, "autosave: chat") {
+		t.Fatalf("expected the session to be shown, got %q", got)
+	}
+}
+
+func TestWithStatus(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		status  string
+		height  int
+		want    string
+	}{
+		{name: "Idle", content: "", status: "status", height: 5, want: "status\n"},
+		{name: "Content", content: "a\nb", status: "status", height: 5, want: "a\nb\nstatus"},
+		{name: "Truncated", content: "a\nb\nc\nd", status: "status", height: 3, want: "c\nd\nstatus"},
+		{name: "NoStatus", content: "a\nb\nc\nd", status: "", height: 3, want: "b\nc\nd"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := withStatus(tc.content, tc.status, tc.height); got != tc.want {
+				t.Errorf("withStatus() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
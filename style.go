@@ -1,36 +1,97 @@
 package tui
 
 import (
+	"sync"
+	"sync/atomic"
+
 	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
 	"github.com/pterm/pterm"
 )
 
+var (
+	defaultPromptStyle    = color.New(color.FgGreen)
+	defaultErrorStyle     = color.New(color.FgRed)
+	defaultInputEchoStyle = color.New(color.FgGreen)
+	defaultPadding        = []int{0, 1}
+)
+
 var (
 	MarkdownRender *glamour.TermRenderer
-	BoxStyle       = lipgloss.NewStyle().
-			BorderStyle(lipgloss.NormalBorder()).
-			PaddingLeft(1).
-			PaddingRight(1).
-			MarginLeft(4).
-			MarginBottom(1).
-			MaxWidth(pterm.GetTerminalWidth() - 4)
+	BoxStyle       lipgloss.Style
+	WarningStyle   = color.New(color.FgHiRed, color.Bold)
+	PromptStyle    = defaultPromptStyle
+	ErrorStyle     = defaultErrorStyle
+	InputEchoStyle = defaultInputEchoStyle
+	// ToolCallStyle colors the arguments of tool calls, they are not colored if it is nil
+	ToolCallStyle *color.Color
+
+	markdownStyles ansi.StyleConfig
+	boxBorder      *lipgloss.Border
+	boxPadding     = defaultPadding
+	styleLock      sync.RWMutex
+	// styleGeneration changes when the styles are rebuilt so cached renders can be invalidated
+	styleGeneration atomic.Int64
 )
 
 func markdownBox(contentType, content string) string {
-	output, err := MarkdownRender.Render("```" + contentType + "\n" + content + "\n```")
+	output, err := renderMarkdown("```" + contentType + "\n" + content + "\n```")
 	if err == nil {
 		content = output
 	}
+	return renderBox(content)
+}
+
+func renderMarkdown(content string) (string, error) {
+	styleLock.RLock()
+	defer styleLock.RUnlock()
+	return MarkdownRender.Render(content)
+}
+
+func renderBox(content string) string {
+	styleLock.RLock()
+	defer styleLock.RUnlock()
 	return BoxStyle.Render(content)
 }
 
-func init() {
+// resizeStyles rebuilds the styles that depend on the width of the terminal.
+func resizeStyles(width int) error {
+	styleLock.RLock()
+	styles, border, padding := markdownStyles, boxBorder, boxPadding
+	styleLock.RUnlock()
+
 	r, err := glamour.NewTermRenderer(
-		glamour.WithWordWrap(pterm.GetTerminalWidth()-10),
-		glamour.WithStylePath("auto"))
+		glamour.WithWordWrap(width-10),
+		glamour.WithStyles(styles))
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	box := lipgloss.NewStyle().
+		Padding(padding...).
+		MarginLeft(4).
+		MarginBottom(1).
+		MaxWidth(width - 4)
+	if border != nil {
+		box = box.BorderStyle(*border)
 	}
+
+	styleLock.Lock()
 	MarkdownRender = r
+	BoxStyle = box
+	styleLock.Unlock()
+
+	styleGeneration.Add(1)
+	return nil
+}
+
+func init() {
+	border := lipgloss.NormalBorder()
+	markdownStyles, _ = loadMarkdownStyles("")
+	boxBorder = &border
+	if err := resizeStyles(pterm.GetTerminalWidth()); err != nil {
+		panic(err)
+	}
 }
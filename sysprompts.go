@@ -0,0 +1,228 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	godiffpatch "github.com/sourcegraph/go-diff-patch"
+	"golang.org/x/exp/maps"
+)
+
+const maxListedFiles = 20
+
+func toReadPrompt(event gptscript.Frame, offerAlways bool) (ConfirmPrompt, bool) {
+	data := inputArgs(event)
+	filename, _ := data["filename"].(string)
+	if filename == "" {
+		return ConfirmPrompt{}, false
+	}
+
+	resolved := resolvePath(filename)
+	msg := &strings.Builder{}
+	msg.WriteString("Read ")
+	msg.WriteString(resolved)
+	if info, err := os.Stat(resolved); err == nil {
+		msg.WriteString(" (")
+		msg.WriteString(formatSize(info.Size()))
+		msg.WriteString(")")
+	}
+
+	return confirmPrompt(msg.String(), " (or allow all reads under "+filepath.Dir(resolved)+")", Trusted{
+		ToolName:   "read",
+		PathPrefix: filepath.Dir(resolved),
+	}, offerAlways), true
+}
+
+func toListPrompt(event gptscript.Frame, offerAlways bool) (ConfirmPrompt, bool) {
+	data := inputArgs(event)
+	dir, _ := data["dir"].(string)
+	resolved := resolvePath(first(dir, "."))
+
+	// Rules only match calls that name a directory, so there is nothing to always allow without one
+	return confirmPrompt("List the contents of "+resolved, " (or allow listing all directories under "+resolved+")", Trusted{
+		ToolName:   "ls",
+		PathPrefix: resolved,
+	}, offerAlways && dir != ""), true
+}
+
+func toAppendPrompt(event gptscript.Frame, workspace string, offerAlways bool) (ConfirmPrompt, bool) {
+	data := inputArgs(event)
+	filename, _ := data["filename"].(string)
+	content, _ := data["content"].(string)
+	if filename == "" || content == "" {
+		return ConfirmPrompt{}, false
+	}
+
+	existing, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return ConfirmPrompt{}, false
+	}
+
+	var (
+		resolved = resolvePath(filename)
+		dir      = filepath.Dir(resolved)
+		patch    = godiffpatch.GeneratePatch(filepath.Base(filename), string(existing), string(existing)+content)
+		msg      = &strings.Builder{}
+	)
+
+	msg.WriteString(markdownBox("diff", patch))
+	msg.WriteString("\n")
+	warning := workspaceWarning(resolved, workspace)
+	msg.WriteString(warning)
+	msg.WriteString("Append to ")
+	msg.WriteString(filename)
+
+	// Rules never cover appends outside the workspace, so there is nothing to always allow
+	return confirmPrompt(msg.String(), " (or allow all appends under "+dir+")", Trusted{
+		ToolName:   "append",
+		PathPrefix: dir,
+	}, offerAlways && warning == ""), true
+}
+
+func toRemovePrompt(event gptscript.Frame, workspace string) (ConfirmPrompt, bool) {
+	data := inputArgs(event)
+	location, _ := data["location"].(string)
+	if location == "" {
+		return ConfirmPrompt{}, false
+	}
+
+	var (
+		resolved  = resolvePath(location)
+		files     = map[string]int64{}
+		total     int64
+		truncated bool
+	)
+	// Stop walking once there are more files than are listed, removing / shouldn't walk the whole disk
+	_ = filepath.WalkDir(resolved, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if len(files) == maxListedFiles {
+			truncated = true
+			return fs.SkipAll
+		}
+		if info, err := d.Info(); err == nil {
+			files[path] = info.Size()
+			total += info.Size()
+		}
+		return nil
+	})
+
+	msg := &strings.Builder{}
+	names := maps.Keys(files)
+	sort.Strings(names)
+	for _, name := range names {
+		msg.WriteString(fmt.Sprintf("  %10s  %s\n", formatSize(files[name]), name))
+	}
+	if truncated {
+		msg.WriteString("  ...\n")
+	}
+	msg.WriteString(workspaceWarning(resolved, workspace))
+	msg.WriteString(WarningStyle.Sprintf("Remove %s", resolved))
+	if truncated {
+		msg.WriteString(fmt.Sprintf(" (more than %d files, over %s)", len(names), formatSize(total)))
+	} else if len(names) > 0 {
+		msg.WriteString(fmt.Sprintf(" (%d files, %s)", len(names), formatSize(total)))
+	}
+	msg.WriteString("\nConfirm (y/n)")
+
+	return ConfirmPrompt{
+		Message: msg.String(),
+	}, true
+}
+
+func toDownloadPrompt(event gptscript.Frame, workspace string, offerAlways bool) (ConfirmPrompt, bool) {
+	data := inputArgs(event)
+	rawURL, _ := data["url"].(string)
+	location, _ := data["location"].(string)
+	u, err := url.Parse(rawURL)
+	if rawURL == "" || err != nil || u.Host == "" {
+		return ConfirmPrompt{}, false
+	}
+
+	var (
+		msg     = &strings.Builder{}
+		warning string
+	)
+	msg.WriteString("Download ")
+	msg.WriteString(redactURL(rawURL))
+	// The size is unknown, asking the server would access the network before the user allowed it
+	msg.WriteString(" (size unknown)")
+	if location != "" {
+		resolved := resolvePath(location)
+		msg.WriteString(" to ")
+		msg.WriteString(resolved)
+		warning = workspaceWarning(resolved, workspace)
+	}
+	msg.WriteString("\n")
+	msg.WriteString(warning)
+
+	// Rules never cover downloads to outside the workspace, so there is nothing to always allow
+	prefix := u.Scheme + "://" + u.Host + "/"
+	return confirmPrompt(msg.String(), "(or allow all downloads from "+prefix+")", Trusted{
+		ToolName: "download",
+		ArgPrefix: map[string]string{
+			"url": prefix,
+		},
+	}, offerAlways && warning == ""), true
+}
+
+func toHTTPPrompt(toolName string, event gptscript.Frame, offerAlways bool) (ConfirmPrompt, bool) {
+	data := inputArgs(event)
+	rawURL, _ := data["url"].(string)
+	u, err := url.Parse(rawURL)
+	if rawURL == "" || err != nil || u.Host == "" {
+		return ConfirmPrompt{}, false
+	}
+
+	method := http.MethodGet
+	if toolName == "http.post" {
+		method = http.MethodPost
+	}
+
+	msg := &strings.Builder{}
+	msg.WriteString(method)
+	msg.WriteString(" ")
+	msg.WriteString(redactURL(rawURL))
+	msg.WriteString("\n")
+
+	headers := map[string]string{}
+	if contentType, _ := data["contentType"].(string); contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+	if extra, ok := data["headers"].(map[string]any); ok {
+		for key, val := range extra {
+			headers[key] = fmt.Sprint(val)
+		}
+	}
+	keys := maps.Keys(headers)
+	sort.Strings(keys)
+	for _, key := range keys {
+		val := headers[key]
+		if isSecretName(key) {
+			val = redacted
+		}
+		msg.WriteString(fmt.Sprintf("  %s: %s\n", key, val))
+	}
+
+	if content, _ := data["content"].(string); content != "" {
+		msg.WriteString(markdownBox("", content))
+		msg.WriteString("\n")
+	}
+
+	prefix := u.Scheme + "://" + u.Host + "/"
+	return confirmPrompt(msg.String(), fmt.Sprintf("(or allow all %s requests to %s)", method, prefix), Trusted{
+		ToolName: toolName,
+		ArgPrefix: map[string]string{
+			"url": prefix,
+		},
+	}, offerAlways), true
+}
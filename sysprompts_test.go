@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+func TestRemovePromptLimitsWalk(t *testing.T) {
+	dir := t.TempDir()
+	for i := range maxListedFiles + 5 {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file-%02d", i)), []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prompt, ok := toRemovePrompt(sysEvent("#!sys.remove", `{"location": "`+dir+`"}`), dir)
+	if !ok {
+		t.Fatal("expected a prompt")
+	}
+	if !strings.Contains(prompt.Message, fmt.Sprintf("more than %d files", maxListedFiles)) {
+		t.Fatalf("expected a rough total, got:\n%s", prompt.Message)
+	}
+	if got := strings.Count(prompt.Message, "file-"); got != maxListedFiles {
+		t.Fatalf("expected %d listed files, got %d", maxListedFiles, got)
+	}
+}
+
+func TestDownloadPromptDoesNotAccessNetwork(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	prompt, ok := toDownloadPrompt(sysEvent("#!sys.download", `{"url": "`+server.URL+`/file?secret=1"}`), t.TempDir(), true)
+	if !ok {
+		t.Fatal("expected a prompt")
+	}
+	if requests != 0 {
+		t.Fatalf("expected no requests before confirming, got %d", requests)
+	}
+	if !strings.Contains(prompt.Message, "size unknown") {
+		t.Fatalf("expected unknown size, got:\n%s", prompt.Message)
+	}
+}
+
+func TestReadAndListRulesOutsideWorkspace(t *testing.T) {
+	workspace := t.TempDir()
+	outside := t.TempDir()
+	c := &Confirm{workspace: workspace}
+
+	for _, tc := range []struct {
+		tool  string
+		input string
+	}{
+		{tool: "read", input: `{"filename": "` + filepath.Join(outside, "a.txt") + `"}`},
+		{tool: "ls", input: `{"dir": "` + outside + `"}`},
+	} {
+		event := sysEvent("#!sys."+tc.tool, tc.input)
+		prompt := c.toSysConfirmMessage(tc.tool, event, true)
+		if prompt.AlwaysTrust.ToolName == "" {
+			t.Fatalf("expected %s to offer an always rule, got %v", tc.tool, prompt)
+		}
+		c.always = append(c.always, prompt.AlwaysTrust)
+		if _, ok := c.isAlways(event); !ok {
+			t.Errorf("expected the %s rule to match outside the workspace", tc.tool)
+		}
+	}
+
+	prompt := c.toSysConfirmMessage("ls", sysEvent("#!sys.ls", `{}`), true)
+	if prompt.AlwaysTrust.ToolName != "" || !strings.HasSuffix(prompt.Message, "(y/n)") {
+		t.Fatalf("expected ls without a dir to offer no always rule, got %v", prompt)
+	}
+
+	input := `{"filename": "` + filepath.Join(outside, "a.txt") + `", "content": "hello"}`
+	prompt = c.toSysConfirmMessage("append", sysEvent("#!sys.append", input), true)
+	if prompt.AlwaysTrust.ToolName != "" || !strings.HasSuffix(prompt.Message, "(y/n)") {
+		t.Fatalf("expected append outside the workspace to offer no always rule, got %v", prompt)
+	}
+}
+
+func TestDownloadRuleOutsideWorkspace(t *testing.T) {
+	workspace := t.TempDir()
+	outside := t.TempDir()
+	c := &Confirm{workspace: workspace}
+
+	download := func(location string) gptscript.Frame {
+		return sysEvent("#!sys.download", `{"url": "https://example.com/a", "location": "`+location+`"}`)
+	}
+
+	prompt := c.toSysConfirmMessage("download", download(filepath.Join(outside, "a")), true)
+	if prompt.AlwaysTrust.ToolName != "" || !strings.HasSuffix(prompt.Message, "(y/n)") {
+		t.Fatalf("expected a download outside the workspace to offer no always rule, got %v", prompt)
+	}
+
+	prompt = c.toSysConfirmMessage("download", download(filepath.Join(workspace, "a")), true)
+	if prompt.AlwaysTrust.ToolName == "" {
+		t.Fatalf("expected a download into the workspace to offer an always rule, got %v", prompt)
+	}
+	c.always = append(c.always, prompt.AlwaysTrust)
+	if _, ok := c.isAlways(download(filepath.Join(workspace, "b"))); !ok {
+		t.Error("expected the rule to cover downloads into the workspace")
+	}
+	if _, ok := c.isAlways(download(filepath.Join(outside, "b"))); ok {
+		t.Error("expected the rule not to cover downloads to outside the workspace")
+	}
+}
+
+func TestPromptsWithoutAlways(t *testing.T) {
+	c := &Confirm{}
+
+	for _, tc := range []struct {
+		tool         string
+		instructions string
+		input        string
+	}{
+		{tool: "exec", instructions: "#!sys.exec", input: `{"command": "ls -la"}`},
+		{tool: "write", instructions: "#!sys.write", input: `{"filename": "a.txt", "content": "a"}`},
+		{tool: "read", instructions: "#!sys.read", input: `{"filename": "a.txt"}`},
+		{tool: "ls", instructions: "#!sys.ls", input: `{"dir": "."}`},
+		{tool: "download", instructions: "#!sys.download", input: `{"url": "https://example.com/a"}`},
+		{tool: "http.get", instructions: "#!sys.http.get", input: `{"url": "https://example.com/a"}`},
+		{tool: "mcp.invoke github get_issue", instructions: "#!sys.mcp.invoke github get_issue", input: `{"id": 1}`},
+		{tool: "custom", instructions: "#!sys.custom", input: `{}`},
+	} {
+		prompt := c.toSysConfirmMessage(tc.tool, sysEvent(tc.instructions, tc.input), false)
+		if prompt.AlwaysTrust.ToolName != "" || prompt.ServerTrust.ToolName != "" {
+			t.Errorf("expected %s to offer no rule, got %v", tc.tool, prompt)
+		}
+		if strings.Contains(prompt.Message, "allow") || !strings.HasSuffix(prompt.Message, "\nConfirm (y/n)") {
+			t.Errorf("expected %s to offer no rule, got:\n%s", tc.tool, prompt.Message)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"github.com/muesli/termenv"
+	"github.com/pterm/pterm"
+	"golang.org/x/exp/maps"
+)
+
+// Theme configures how the output looks. Fields that are not set keep the default look.
+type Theme struct {
+	// Markdown is a glamour style name, like dark, light, notty or dracula, or the path to a JSON style file.
+	// The default picks dark or light based on the background of the terminal.
+	Markdown string
+	// Border of boxes around tool output, one of normal, rounded, thick, double, hidden or none
+	Border string
+	// Padding inside boxes, in the order of lipgloss.Style.Padding
+	Padding []int
+	// NoColor disables all colors
+	NoColor   bool
+	Prompt    *color.Color
+	Error     *color.Color
+	InputEcho *color.Color
+	ToolCall  *color.Color
+}
+
+const (
+	ThemeDefault      = "default"
+	ThemeHighContrast = "high-contrast"
+	ThemeNoColor      = "no-color"
+)
+
+var builtinThemes = map[string]func() *Theme{
+	ThemeDefault: func() *Theme {
+		return &Theme{}
+	},
+	ThemeHighContrast: func() *Theme {
+		return &Theme{
+			Markdown:  glamour.DarkStyle,
+			Border:    "thick",
+			Prompt:    color.New(color.FgHiYellow, color.Bold),
+			Error:     color.New(color.FgHiWhite, color.BgRed, color.Bold),
+			InputEcho: color.New(color.FgHiCyan, color.Bold),
+			ToolCall:  color.New(color.FgHiMagenta, color.Bold),
+		}
+	},
+	ThemeNoColor: func() *Theme {
+		return &Theme{
+			Markdown: glamour.NoTTYStyle,
+			Border:   "normal",
+			NoColor:  true,
+		}
+	},
+}
+
+var (
+	autoMarkdownStyles ansi.StyleConfig
+	detectStyles       sync.Once
+)
+
+var borders = map[string]lipgloss.Border{
+	"normal":  lipgloss.NormalBorder(),
+	"rounded": lipgloss.RoundedBorder(),
+	"thick":   lipgloss.ThickBorder(),
+	"double":  lipgloss.DoubleBorder(),
+	"hidden":  lipgloss.HiddenBorder(),
+}
+
+// BuiltinTheme returns a copy of a built-in theme by name, see ThemeNames.
+func BuiltinTheme(name string) (*Theme, error) {
+	theme, ok := builtinThemes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme %q, available themes are %v", name, ThemeNames())
+	}
+	return theme(), nil
+}
+
+func ThemeNames() []string {
+	names := maps.Keys(builtinThemes)
+	sort.Strings(names)
+	return names
+}
+
+// applyTheme sets the styles of the package from theme. Colors are disabled for any theme if NO_COLOR is set.
+func applyTheme(theme *Theme) error {
+	if theme == nil {
+		theme = builtinThemes[ThemeDefault]()
+	}
+
+	noColor := theme.NoColor || os.Getenv("NO_COLOR") != ""
+	markdown := theme.Markdown
+	if noColor {
+		// Any other markdown style has colors
+		markdown = glamour.NoTTYStyle
+	}
+
+	styles, err := loadMarkdownStyles(markdown)
+	if err != nil {
+		return err
+	}
+
+	var border *lipgloss.Border
+	if theme.Border != "none" {
+		b, ok := borders[first(theme.Border, "normal")]
+		if !ok {
+			return fmt.Errorf("unknown border %q", theme.Border)
+		}
+		border = &b
+	}
+
+	padding := theme.Padding
+	if len(padding) == 0 {
+		padding = defaultPadding
+	}
+
+	if noColor {
+		color.NoColor = true
+	}
+
+	styleLock.Lock()
+	markdownStyles = styles
+	boxBorder = border
+	boxPadding = padding
+	styleLock.Unlock()
+
+	PromptStyle = first(theme.Prompt, defaultPromptStyle)
+	ErrorStyle = first(theme.Error, defaultErrorStyle)
+	InputEchoStyle = first(theme.InputEcho, defaultInputEchoStyle)
+	ToolCallStyle = theme.ToolCall
+
+	return resizeStyles(pterm.GetTerminalWidth())
+}
+
+func loadMarkdownStyles(style string) (ansi.StyleConfig, error) {
+	if style == "" || style == glamour.AutoStyle {
+		// Detecting the background queries the terminal, so it's only done once
+		detectStyles.Do(func() {
+			autoMarkdownStyles = glamour.LightStyleConfig
+			if termenv.HasDarkBackground() {
+				autoMarkdownStyles = glamour.DarkStyleConfig
+			}
+		})
+		return autoMarkdownStyles, nil
+	}
+
+	if styles, ok := glamour.DefaultStyles[style]; ok {
+		return *styles, nil
+	}
+
+	var styles ansi.StyleConfig
+	data, err := os.ReadFile(style)
+	if err != nil {
+		return styles, fmt.Errorf("unknown markdown style %q: %w", style, err)
+	}
+	if err := json.Unmarshal(data, &styles); err != nil {
+		return styles, fmt.Errorf("invalid markdown style %s: %w", style, err)
+	}
+	return styles, nil
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+)
+
+func TestApplyTheme(t *testing.T) {
+	defer func() {
+		_ = applyTheme(nil)
+	}()
+
+	theme, err := BuiltinTheme(ThemeHighContrast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := applyTheme(theme); err != nil {
+		t.Fatal(err)
+	}
+	if ErrorStyle != theme.Error || PromptStyle != theme.Prompt || ToolCallStyle != theme.ToolCall {
+		t.Fatal("expected theme colors to be applied")
+	}
+	if !strings.Contains(renderBox("x"), lipgloss.ThickBorder().Top) {
+		t.Fatalf("expected a thick border, got %q", renderBox("x"))
+	}
+
+	if err := applyTheme(&Theme{Border: "none", Padding: []int{0}}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(renderBox("x")) != "x" {
+		t.Fatalf("expected no border, got %q", renderBox("x"))
+	}
+	if ErrorStyle != defaultErrorStyle || ToolCallStyle != nil {
+		t.Fatal("expected default colors for unset fields")
+	}
+
+	style := filepath.Join(t.TempDir(), "style.json")
+	if err := os.WriteFile(style, []byte(`{"document": {"margin": 7}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyTheme(&Theme{Markdown: style}); err != nil {
+		t.Fatal(err)
+	}
+	if markdownStyles.Document.Margin == nil || *markdownStyles.Document.Margin != 7 {
+		t.Fatal("expected markdown style to be loaded from the JSON file")
+	}
+
+	for _, theme := range []*Theme{{Border: "wavy"}, {Markdown: "missing"}} {
+		if err := applyTheme(theme); err == nil {
+			t.Errorf("expected an error for %+v", theme)
+		}
+	}
+	if _, err := BuiltinTheme("missing"); err == nil {
+		t.Error("expected an error for an unknown theme")
+	}
+}
+
+func TestApplyThemeNoColorEnv(t *testing.T) {
+	noColor := color.NoColor
+	// Runs after NO_COLOR is restored
+	t.Cleanup(func() {
+		color.NoColor = noColor
+		_ = applyTheme(nil)
+	})
+
+	t.Setenv("NO_COLOR", "1")
+	for _, name := range []string{ThemeDefault, ThemeHighContrast} {
+		theme, err := BuiltinTheme(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := applyTheme(theme); err != nil {
+			t.Fatal(err)
+		}
+		if !color.NoColor || markdownStyles.Document.Color != nil || markdownStyles.Heading.Color != nil {
+			t.Fatalf("expected colors of the %s theme to be disabled by NO_COLOR", name)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+// Price is the cost of a model in dollars per million tokens.
+type Price struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// usage is the token usage of a call or turn, with the estimated cost if the models are in the price table.
+type usage struct {
+	gptscript.Usage
+	Cost float64
+	// Unpriced is set when some tokens were used by a model with no price
+	Unpriced bool
+}
+
+// callModel returns the model of a call without the provider, falling back to the default model.
+func callModel(call gptscript.CallFrame, defaultModel string) string {
+	model := call.Tool.ModelName
+	if model == "" {
+		model = defaultModel
+	}
+	model, _, _ = strings.Cut(model, " from ")
+	return strings.TrimSpace(model)
+}
+
+func (u *usage) add(call gptscript.CallFrame, prices map[string]Price, defaultModel string) {
+	if call.Usage.TotalTokens == 0 && call.Usage.PromptTokens == 0 && call.Usage.CompletionTokens == 0 {
+		return
+	}
+
+	u.PromptTokens += call.Usage.PromptTokens
+	u.CompletionTokens += call.Usage.CompletionTokens
+	total := call.Usage.TotalTokens
+	if total == 0 {
+		total = call.Usage.PromptTokens + call.Usage.CompletionTokens
+	}
+	u.TotalTokens += total
+
+	price, ok := prices[callModel(call, defaultModel)]
+	if !ok {
+		u.Unpriced = true
+		return
+	}
+	u.Cost += (float64(call.Usage.PromptTokens)*price.Prompt + float64(call.Usage.CompletionTokens)*price.Completion) / 1_000_000
+}
+
+func turnUsage(calls gptscript.CallFrames, prices map[string]Price, defaultModel string) usage {
+	var result usage
+	for _, call := range calls {
+		result.add(call, prices, defaultModel)
+	}
+	return result
+}
+
+func (u usage) String() string {
+	if u.TotalTokens == 0 {
+		return ""
+	}
+	s := fmt.Sprintf("Tokens: %s prompt + %s completion = %s", formatCount(u.PromptTokens),
+		formatCount(u.CompletionTokens), formatCount(u.TotalTokens))
+	if u.Cost > 0 {
+		s += fmt.Sprintf(", ~$%.4f", u.Cost)
+		if u.Unpriced {
+			s += " (some models have no price)"
+		}
+	}
+	return s
+}
+
+func formatCount(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 10_000:
+		return fmt.Sprintf("%.0fk", float64(n)/1_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	}
+	return fmt.Sprint(n)
+}
+
+// withUsage adds a status line with the token usage of the calls under the rendered text.
+func withUsage(text func() string, calls gptscript.CallFrames, prices map[string]Price, defaultModel string) func() string {
+	line := turnUsage(calls, prices, defaultModel).String()
+	if line == "" {
+		return text
+	}
+	return func() string {
+		return strings.TrimRight(text(), "\n") + "\n\n" + color.HiBlackString(line) + "\n"
+	}
+}
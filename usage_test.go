@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+func TestTurnUsage(t *testing.T) {
+	call := func(model string, prompt, completion int) gptscript.CallFrame {
+		c := gptscript.CallFrame{
+			Usage: gptscript.Usage{
+				PromptTokens:     prompt,
+				CompletionTokens: completion,
+				TotalTokens:      prompt + completion,
+			},
+		}
+		c.Tool.ModelName = model
+		return c
+	}
+
+	prices := map[string]Price{
+		"gpt-4o": {Prompt: 2.5, Completion: 10},
+	}
+	calls := gptscript.CallFrames{
+		"1": call("", 1000, 500),
+		"2": call("gpt-4o from github.com/gptscript-ai/openai-provider", 2000, 0),
+		"3": call("", 0, 0),
+	}
+
+	u := turnUsage(calls, prices, "gpt-4o")
+	if u.PromptTokens != 3000 || u.CompletionTokens != 500 || u.TotalTokens != 3500 || u.Unpriced {
+		t.Fatalf("unexpected usage %+v", u)
+	}
+	if got, want := u.String(), "Tokens: 3.0k prompt + 500 completion = 3.5k, ~$0.0125"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	u = turnUsage(calls, prices, "other")
+	if !u.Unpriced || u.String() != "Tokens: 3.0k prompt + 500 completion = 3.5k, ~$0.0050 (some models have no price)" {
+		t.Fatalf("unexpected usage %+v: %s", u, u)
+	}
+
+	if s := turnUsage(nil, nil, "").String(); s != "" {
+		t.Fatalf("expected no usage line, got %q", s)
+	}
+}